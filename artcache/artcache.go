@@ -0,0 +1,252 @@
+/*
+Package artcache keeps album and station art on disk so every image is
+downloaded only once no matter how many UIs ask for it.
+*/
+package artcache
+
+import (
+	"container/list"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Size of art. Its String form is what request.UserLogin.StationArtSize and
+// request.GetStationList.StationArtSize expect.
+type Size struct {
+	Width, Height int
+}
+
+// Sizes Pandora commonly serves.
+var (
+	Small  = Size{130, 130}
+	Medium = Size{500, 500}
+	Large  = Size{1080, 1080}
+)
+
+func (s Size) String() string {
+	return fmt.Sprintf("W%dH%d", s.Width, s.Height)
+}
+
+var sizeSuffix = regexp.MustCompile(`_\d+W_\d+H(\.\w+)$`)
+
+// Resize rewrites an album art URL ending in _<width>W_<height>H.<ext>, as
+// Pandora's are, to ask for another size. Other URLs are returned unchanged.
+func Resize(url string, s Size) string {
+	return sizeSuffix.ReplaceAllString(url, fmt.Sprintf("_%dW_%dH$1", s.Width, s.Height))
+}
+
+// Cache stores downloaded art in a directory and removes the least recently
+// used files once they take up more than the size limit.
+// It is safe for concurrent use; concurrent requests for the same URL are
+// served by a single download.
+type Cache struct {
+	// HTTPClient is used for downloads, defaults to http.DefaultClient.
+	HTTPClient *http.Client
+
+	dir      string
+	maxBytes int64
+
+	mu      sync.Mutex
+	lru     *list.List // of *entry, most recently used first
+	entries map[string]*list.Element
+	size    int64
+	calls   map[string]*call
+}
+
+type entry struct {
+	key  string
+	size int64
+}
+
+type call struct {
+	done chan struct{}
+	err  error
+}
+
+// New returns a Cache storing at most maxBytes in dir. Files already in dir
+// from an earlier run are kept, ordered by modification time.
+func New(dir string, maxBytes int64) (*Cache, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].ModTime().After(files[j].ModTime())
+	})
+
+	c := &Cache{
+		dir:      dir,
+		maxBytes: maxBytes,
+		lru:      list.New(),
+		entries:  make(map[string]*list.Element),
+		calls:    make(map[string]*call),
+	}
+	for _, fi := range files {
+		if !fi.Mode().IsRegular() || len(fi.Name()) != sha1.Size*2 {
+			continue
+		}
+		c.entries[fi.Name()] = c.lru.PushBack(&entry{key: fi.Name(), size: fi.Size()})
+		c.size += fi.Size()
+	}
+
+	c.mu.Lock()
+	c.evict("")
+	c.mu.Unlock()
+
+	return c, nil
+}
+
+// Get returns the image at url, downloading it if it is not cached.
+func (c *Cache) Get(url string) ([]byte, error) {
+	var data []byte
+	_, err := c.fetch(url, func(path string) (err error) {
+		data, err = ioutil.ReadFile(path)
+		return err
+	})
+	return data, err
+}
+
+// Path returns the file holding the image at url, downloading it if it is
+// not cached. The file may be removed again by later downloads.
+func (c *Cache) Path(url string) (string, error) {
+	return c.fetch(url, nil)
+}
+
+// fetch makes sure the image at url is cached and returns its file. If use
+// is not nil, it is called with the file while c.mu is held, so the file
+// cannot be evicted in the meantime.
+func (c *Cache) fetch(url string, use func(path string) error) (string, error) {
+	key := hashKey(url)
+	path := filepath.Join(c.dir, key)
+
+	for {
+		c.mu.Lock()
+		if el, ok := c.entries[key]; ok {
+			c.lru.MoveToFront(el)
+			var err error
+			if use != nil {
+				err = use(path)
+			}
+			c.mu.Unlock()
+
+			now := time.Now()
+			os.Chtimes(path, now, now)
+			return path, err
+		}
+
+		cl, ok := c.calls[key]
+		if !ok {
+			break
+		}
+		c.mu.Unlock()
+		<-cl.done
+		if cl.err != nil {
+			return path, cl.err
+		}
+		// Look the file up again, it may have been evicted already.
+	}
+
+	cl := &call{done: make(chan struct{})}
+	c.calls[key] = cl
+	c.mu.Unlock()
+
+	size, err := c.download(url, path)
+
+	c.mu.Lock()
+	useErr := err
+	if err == nil {
+		c.entries[key] = c.lru.PushFront(&entry{key: key, size: size})
+		c.size += size
+		c.evict(key)
+		if use != nil {
+			useErr = use(path)
+		}
+	}
+	delete(c.calls, key)
+	c.mu.Unlock()
+
+	cl.err = err
+	close(cl.done)
+
+	return path, useErr
+}
+
+// Size returns the number of bytes stored.
+func (c *Cache) Size() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.size
+}
+
+func (c *Cache) download(url, path string) (int64, error) {
+	client := c.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Get(url)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("artcache: fetching %s: %s", url, resp.Status)
+	}
+
+	tmp, err := ioutil.TempFile(c.dir, ".download-")
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(tmp.Name())
+
+	size, err := io.Copy(tmp, resp.Body)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	return size, os.Rename(tmp.Name(), path)
+}
+
+// evict removes the least recently used files until the cache fits its
+// limit, never removing keep. c.mu must be held.
+func (c *Cache) evict(keep string) {
+	for c.size > c.maxBytes {
+		el := c.lru.Back()
+		if el == nil {
+			return
+		}
+		e := el.Value.(*entry)
+		if e.key == keep {
+			return
+		}
+
+		os.Remove(filepath.Join(c.dir, e.key))
+		c.lru.Remove(el)
+		delete(c.entries, e.key)
+		c.size -= e.size
+	}
+}
+
+func hashKey(url string) string {
+	sum := sha1.Sum([]byte(url))
+	return hex.EncodeToString(sum[:])
+}
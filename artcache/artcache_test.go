@@ -0,0 +1,129 @@
+package artcache
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestResize(t *testing.T) {
+	data := []struct {
+		URL, Expected string
+	}{
+		{"http://p-cdn.com/images/abc_130W_130H.jpg", "http://p-cdn.com/images/abc_500W_500H.jpg"},
+		{"http://p-cdn.com/images/abc.jpg", "http://p-cdn.com/images/abc.jpg"},
+	}
+	for _, d := range data {
+		if out := Resize(d.URL, Medium); out != d.Expected {
+			t.Errorf("\nexpected:\n\t%q\ngot:\n\t%q", d.Expected, out)
+		}
+	}
+
+	if s := Small.String(); s != "W130H130" {
+		t.Errorf("expected W130H130, got %q", s)
+	}
+}
+
+func TestCache(t *testing.T) {
+	var hits int32
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		<-release
+		w.Write(bytes.Repeat([]byte(r.URL.Path[1:2]), 10))
+	}))
+	defer srv.Close()
+
+	dir, err := ioutil.TempDir("", "artcache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	c, err := New(dir, 25)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Concurrent requests share one download.
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if data, err := c.Get(srv.URL + "/a"); err != nil || string(data) != "aaaaaaaaaa" {
+				t.Errorf("unexpected result %q, %v", data, err)
+			}
+		}()
+	}
+	close(release)
+	wg.Wait()
+	if hits := atomic.LoadInt32(&hits); hits != 1 {
+		t.Errorf("expected 1 download, got %d", hits)
+	}
+
+	c.Get(srv.URL + "/b")
+	c.Get(srv.URL + "/a") // a is now more recent than b
+	c.Get(srv.URL + "/c") // evicts b
+	if size := c.Size(); size != 20 {
+		t.Errorf("expected 20 bytes cached, got %d", size)
+	}
+
+	c.Get(srv.URL + "/a")
+	if hits := atomic.LoadInt32(&hits); hits != 3 {
+		t.Errorf("expected 3 downloads, got %d", hits)
+	}
+
+	// A new cache picks up the files.
+	c2, err := New(dir, 25)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size := c2.Size(); size != 20 {
+		t.Errorf("expected 20 bytes after reload, got %d", size)
+	}
+	c2.Get(srv.URL + "/c")
+	if hits := atomic.LoadInt32(&hits); hits != 3 {
+		t.Errorf("expected cached file to be reused, %d downloads", hits)
+	}
+}
+
+func TestCacheEvictWhileReading(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(bytes.Repeat([]byte(r.URL.Path[1:2]), 10))
+	}))
+	defer srv.Close()
+
+	dir, err := ioutil.TempDir("", "artcache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// Room for a single image, so every download evicts the others.
+	c, err := New(dir, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(name string) {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				if data, err := c.Get(srv.URL + "/" + name); err != nil || string(data) != strings.Repeat(name, 10) {
+					t.Errorf("%s: unexpected result %q, %v", name, data, err)
+					return
+				}
+			}
+		}(string(rune('a' + i)))
+	}
+	wg.Wait()
+}
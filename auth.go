@@ -3,9 +3,13 @@ package gopiano
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
+	"io/ioutil"
 	"strconv"
+	"strings"
 	"time"
 
+	"denniskupec.com/gopiano/coder"
 	"denniskupec.com/gopiano/request"
 	"denniskupec.com/gopiano/response"
 )
@@ -23,43 +27,53 @@ func (c *Client) AuthPartnerLogin() (*response.AuthPartnerLogin, error) {
 	}
 
 	var resp response.AuthPartnerLogin
-	{
-		var buf bytes.Buffer
-		if err := json.NewEncoder(&buf).Encode(requestData); err != nil {
-			return nil, err
-		}
-
-		res, err := PandoraCall(c.formatURL(requestData), &buf)
-		if err != nil {
-			return nil, err
-		}
-
-		if err := json.Unmarshal(res, &resp); err != nil {
-			return nil, err
-		}
+	if err := c.intercept(c.partnerCall)(requestData, &resp); err != nil {
+		return nil, err
 	}
 
-	syncTime := []byte(resp.SyncTime)
-	if _, err := c.decrypt(syncTime); err != nil {
+	syncTime, err := ioutil.ReadAll(coder.NewDecoder(c.decrypter, strings.NewReader(resp.SyncTime)))
+	if err != nil {
 		return nil, err
 	}
-
-	// syncTime = syncTime[:n] // TODO: why does the next line ignore this?
-	resp.SyncTime = string(syncTime[4:14])
-
-	i, err := strconv.ParseInt(resp.SyncTime, 10, 32)
+	sec, err := parseSyncTime(syncTime)
 	if err != nil {
 		return nil, err
 	}
+	resp.SyncTime = strconv.FormatInt(sec, 10)
 
 	// Set partner data onto client for later use.
-	c.timeOffset = time.Unix(i, 0).Sub(time.Now())
+	now := c.now()
+	c.sessionMu.Lock()
+	defer c.sessionMu.Unlock()
+	c.timeOffset = time.Unix(sec, 0).Sub(now)
+	c.lastSync = now
 	c.partnerAuthToken = resp.PartnerAuthToken
 	c.partnerID = resp.PartnerID
 
 	return &resp, nil
 }
 
+// partnerCall is the Invoker for AuthPartnerLogin. Unlike Call it sends the
+// request unencrypted.
+func (c *Client) partnerCall(req request.Type, data interface{}) (err error) {
+	callURL := c.formatURL(req)
+	start := time.Now()
+	defer func() { c.finishCall(req, callURL, start, err) }()
+
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(req); err != nil {
+		return err
+	}
+
+	res, err := pandoraCall(c.httpClient(), callURL, &buf)
+	if err != nil {
+		return err
+	}
+
+	c.checkSchema(req, res, data)
+	return json.Unmarshal(res, data)
+}
+
 // AuthUserLogin logs in a username and password pair.
 // Receives the UserAuthToken which is used in subsequent calls.
 //
@@ -67,7 +81,7 @@ func (c *Client) AuthPartnerLogin() (*response.AuthPartnerLogin, error) {
 // or UserCreateUser before you proceed.
 func (c *Client) AuthUserLogin(username, password string) (*response.AuthUserLogin, error) {
 	requestData := request.UserLogin{
-		PartnerAuthToken: c.partnerAuthToken,
+		PartnerAuthToken: c.partnerToken(),
 		LoginType:        "user",
 		Username:         username,
 		Password:         password,
@@ -81,8 +95,54 @@ func (c *Client) AuthUserLogin(username, password string) (*response.AuthUserLog
 	}
 
 	// Set user data onto client for later use.
+	c.sessionMu.Lock()
+	defer c.sessionMu.Unlock()
 	c.userAuthToken = resp.UserAuthToken
 	c.userID = resp.UserID
+	c.username = username
+	c.password = password
 
 	return &resp, nil
 }
+
+// Relogin repeats AuthPartnerLogin and AuthUserLogin with the credentials
+// of the last successful AuthUserLogin, e.g. after the auth token expired.
+func (c *Client) Relogin() error {
+	c.sessionMu.Lock()
+	username, password := c.username, c.password
+	c.sessionMu.Unlock()
+	if username == "" {
+		return errors.New("relogin without previous user login")
+	}
+	if c.Metrics != nil {
+		c.Metrics.ObserveRelogin()
+	}
+	if c.Logger != nil {
+		c.Logger.Info("pandora relogin")
+	}
+
+	// Log in from scratch, without the expired token.
+	c.sessionMu.Lock()
+	c.userAuthToken, c.userID = "", ""
+	c.sessionMu.Unlock()
+	if _, err := c.AuthPartnerLogin(); err != nil {
+		return err
+	}
+	_, err := c.AuthUserLogin(username, password)
+	return err
+}
+
+// WithRelogin calls fn and, if it failed because the auth token is no longer
+// valid, logs in again with Relogin and calls fn once more.
+// Long running programs can wrap their calls with it.
+func (c *Client) WithRelogin(fn func() error) error {
+	err := fn()
+	if e, ok := err.(response.ErrorResponse); !ok || e.Code != response.InvalidAuthToken {
+		return err
+	}
+
+	if err := c.Relogin(); err != nil {
+		return err
+	}
+	return fn()
+}
@@ -0,0 +1,259 @@
+package gopiano
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"denniskupec.com/gopiano/response"
+)
+
+// BackupVersion is the version of StationBackup documents written by ExportStations.
+const BackupVersion = 1
+
+// StationBackup is an account independent description of a user's stations,
+// meant to be stored as JSON.
+type StationBackup struct {
+	Version  int             `json:"version"`
+	Created  time.Time       `json:"created"`
+	Stations []BackupStation `json:"stations"`
+}
+
+// BackupStation describes a station by its name, seeds and feedback.
+type BackupStation struct {
+	Name       string        `json:"name"`
+	QuickMix   bool          `json:"quickMix,omitempty"`
+	Seeds      []BackupMusic `json:"seeds"`
+	ThumbsUp   []BackupMusic `json:"thumbsUp,omitempty"`
+	ThumbsDown []BackupMusic `json:"thumbsDown,omitempty"`
+}
+
+// BackupMusic identifies a song, or an artist if SongName is empty, by name.
+type BackupMusic struct {
+	ArtistName string `json:"artistName"`
+	SongName   string `json:"songName,omitempty"`
+}
+
+func (m BackupMusic) String() string {
+	if m.SongName == "" {
+		return m.ArtistName
+	}
+	return m.ArtistName + " - " + m.SongName
+}
+
+// ImportReport lists what ImportStations did.
+type ImportReport struct {
+	Created  []string // names of the created stations
+	Problems []ImportProblem
+}
+
+// ImportProblem is something from a backup that could not be recreated.
+type ImportProblem struct {
+	Station string
+	Music   BackupMusic // empty if the whole station failed
+	Err     error
+}
+
+func (p ImportProblem) String() string {
+	if p.Music == (BackupMusic{}) {
+		return fmt.Sprintf("%s: %v", p.Station, p.Err)
+	}
+	return fmt.Sprintf("%s: %s: %v", p.Station, p.Music, p.Err)
+}
+
+var errNoMatch = errors.New("no matching search result")
+
+// ErrFeedbackNotRestorable is reported for thumbs up and down that cannot be
+// given again: Pandora only accepts feedback for a track token from a
+// playlist, which backups and other accounts do not have.
+var ErrFeedbackNotRestorable = errors.New("feedback is not restorable")
+
+// ExportStations describes all stations of the user, including seeds,
+// feedback and QuickMix membership, in a StationBackup.
+func (c *Client) ExportStations() (*StationBackup, error) {
+	stations, quickMix, err := c.extendedStations()
+	if err != nil {
+		return nil, err
+	}
+
+	inQuickMix := make(map[string]bool)
+	for _, id := range quickMix {
+		inQuickMix[id] = true
+	}
+
+	backup := &StationBackup{
+		Version: BackupVersion,
+		Created: time.Now().UTC(),
+	}
+	for i := range stations {
+		backup.Stations = append(backup.Stations, backupStation(&stations[i], inQuickMix[stations[i].StationID]))
+	}
+
+	return backup, nil
+}
+
+// extendedStations fetches all stations of the user except the QuickMix,
+// with seeds and feedback, along with the IDs of the QuickMix stations.
+func (c *Client) extendedStations() (stations []response.Station, quickMix []string, err error) {
+	list, err := c.UserGetStationList(false)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, s := range list.Stations {
+		if s.IsQuickMix {
+			quickMix = s.QuickMixStationIDs
+			continue
+		}
+
+		resp, err := c.StationGetStation(s.StationToken, true)
+		if err != nil {
+			return nil, nil, err
+		}
+		stations = append(stations, resp.Result)
+	}
+
+	return stations, quickMix, nil
+}
+
+func backupStation(s *response.Station, quickMix bool) BackupStation {
+	b := BackupStation{
+		Name:     s.StationName,
+		QuickMix: quickMix,
+		Seeds:    []BackupMusic{},
+	}
+	for _, a := range s.Music.Artists {
+		b.Seeds = append(b.Seeds, BackupMusic{ArtistName: a.ArtistName})
+	}
+	for _, song := range s.Music.Songs {
+		b.Seeds = append(b.Seeds, BackupMusic{ArtistName: song.ArtistName, SongName: song.SongName})
+	}
+	for _, f := range s.Feedback.ThumbsUp {
+		b.ThumbsUp = append(b.ThumbsUp, BackupMusic{ArtistName: f.ArtistName, SongName: f.SongName})
+	}
+	for _, f := range s.Feedback.ThumbsDown {
+		b.ThumbsDown = append(b.ThumbsDown, BackupMusic{ArtistName: f.ArtistName, SongName: f.SongName})
+	}
+	return b
+}
+
+// ImportStations recreates the stations of a backup in the user's account.
+// Seeds are looked up by name with MusicSearch; anything that cannot be
+// found or added is listed in the report instead of stopping the import.
+// Feedback cannot be restored and is listed as ErrFeedbackNotRestorable.
+func (c *Client) ImportStations(b *StationBackup) (*ImportReport, error) {
+	if b.Version != BackupVersion {
+		return nil, fmt.Errorf("unsupported station backup version %d", b.Version)
+	}
+
+	report := &ImportReport{}
+	var quickMix []string
+	for _, s := range b.Stations {
+		station, err := c.importStation(s, report)
+		if err != nil {
+			report.Problems = append(report.Problems, ImportProblem{Station: s.Name, Err: err})
+			continue
+		}
+
+		report.Created = append(report.Created, station.StationName)
+		if s.QuickMix {
+			quickMix = append(quickMix, station.StationID)
+		}
+	}
+
+	if len(quickMix) > 0 {
+		if err := c.QuickMix().Add(quickMix...); err != nil {
+			return report, err
+		}
+	}
+
+	return report, nil
+}
+
+func (c *Client) importStation(s BackupStation, report *ImportReport) (*response.Station, error) {
+	problem := func(m BackupMusic, err error) {
+		report.Problems = append(report.Problems, ImportProblem{Station: s.Name, Music: m, Err: err})
+	}
+
+	var station *response.Station
+	for _, seed := range s.Seeds {
+		token, err := c.findMusic(seed)
+		if err != nil {
+			problem(seed, err)
+			continue
+		}
+
+		if station == nil {
+			resp, err := c.StationCreateStationMusic(token)
+			if err != nil {
+				problem(seed, err)
+				continue
+			}
+			station = &resp.Result
+		} else if _, err := c.StationAddMusic(token, station.StationToken); err != nil {
+			problem(seed, err)
+		}
+	}
+	if station == nil {
+		return nil, errors.New("none of the seeds could be added")
+	}
+
+	if station.StationName != s.Name {
+		if resp, err := c.StationRenameStation(station.StationToken, s.Name); err == nil {
+			station = &resp.Result
+		} else {
+			report.Problems = append(report.Problems, ImportProblem{Station: s.Name, Err: err})
+		}
+	}
+
+	for _, song := range s.ThumbsUp {
+		problem(song, ErrFeedbackNotRestorable)
+	}
+	for _, song := range s.ThumbsDown {
+		problem(song, ErrFeedbackNotRestorable)
+	}
+
+	return station, nil
+}
+
+// findMusic searches for the music token of a song or artist.
+func (c *Client) findMusic(m BackupMusic) (string, error) {
+	query := m.ArtistName
+	if m.SongName != "" {
+		query += " " + m.SongName
+	}
+
+	res, err := c.MusicSearch(query)
+	if err != nil {
+		return "", err
+	}
+
+	if token, ok := matchMusic(res, m); ok {
+		return token, nil
+	}
+	return "", errNoMatch
+}
+
+// matchMusic picks the search result with exactly the names of m, ignoring case.
+func matchMusic(res *response.MusicSearch, m BackupMusic) (string, bool) {
+	same := func(a, b string) bool {
+		return strings.EqualFold(strings.TrimSpace(a), strings.TrimSpace(b))
+	}
+
+	if m.SongName == "" {
+		for _, a := range res.Artists {
+			if same(a.ArtistName, m.ArtistName) {
+				return a.MusicToken, true
+			}
+		}
+		return "", false
+	}
+
+	for _, s := range res.Songs {
+		if same(s.ArtistName, m.ArtistName) && same(s.SongName, m.SongName) {
+			return s.MusicToken, true
+		}
+	}
+	return "", false
+}
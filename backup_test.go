@@ -0,0 +1,76 @@
+package gopiano
+
+import (
+	"encoding/json"
+	"testing"
+
+	"denniskupec.com/gopiano/request"
+	"denniskupec.com/gopiano/response"
+)
+
+func TestMatchMusic(t *testing.T) {
+	var res response.MusicSearch
+	err := json.Unmarshal([]byte(`{
+		"songs": [
+			{"artistName": "The Artist", "songName": "Song (Live)", "musicToken": "S1"},
+			{"artistName": "The Artist", "songName": "Song", "musicToken": "S2"}
+		],
+		"artists": [
+			{"artistName": "The Artist Tribute Band", "musicToken": "R1"},
+			{"artistName": "The Artist", "musicToken": "R2"}
+		]
+	}`), &res)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := []struct {
+		Music BackupMusic
+		Token string
+	}{
+		{BackupMusic{ArtistName: "the artist"}, "R2"},
+		{BackupMusic{ArtistName: "The Artist", SongName: "song "}, "S2"},
+		{BackupMusic{ArtistName: "The Artist", SongName: "Other Song"}, ""},
+		{BackupMusic{ArtistName: "Someone Else"}, ""},
+	}
+
+	for _, d := range data {
+		token, ok := matchMusic(&res, d.Music)
+		if ok != (d.Token != "") || token != d.Token {
+			t.Errorf("%s: expected %q, got %q", d.Music, d.Token, token)
+		}
+	}
+}
+
+func TestImportStationsFeedback(t *testing.T) {
+	c, _ := NewClient(AndroidClient)
+	c.Interceptors = []Interceptor{func(req request.Type, data interface{}, next Invoker) error {
+		switch req.(type) {
+		case request.MusicSearch:
+			data.(*response.MusicSearch).Artists = []response.SearchArtist{{ArtistName: "Queen", MusicToken: "R1"}}
+		case request.CreateStation:
+			data.(*response.StationCreateStation).Result = response.Station{StationToken: "st", StationName: "Queen"}
+		case request.AddFeedback:
+			t.Error("feedback added with a search result instead of a track token")
+		}
+		return nil
+	}}
+
+	report, err := c.ImportStations(&StationBackup{
+		Version: BackupVersion,
+		Stations: []BackupStation{{
+			Name:     "Queen",
+			Seeds:    []BackupMusic{{ArtistName: "Queen"}},
+			ThumbsUp: []BackupMusic{{ArtistName: "Queen", SongName: "Innuendo"}},
+		}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Created) != 1 {
+		t.Errorf("expected 1 station created, got %v", report.Created)
+	}
+	if len(report.Problems) != 1 || report.Problems[0].Err != ErrFeedbackNotRestorable {
+		t.Errorf("expected thumbs up reported as not restorable, got %v", report.Problems)
+	}
+}
@@ -0,0 +1,51 @@
+package gopiano
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+// redirect sends all requests to a test server, whatever their URL.
+type redirect struct {
+	target *url.URL
+}
+
+func (r redirect) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.URL.Scheme = r.target.Scheme
+	req.URL.Host = r.target.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func benchmarkShareStation(b *testing.B, emails int) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(ioutil.Discard, r.Body)
+		io.WriteString(w, `{"stat": "ok", "result": {}}`)
+	}))
+	defer srv.Close()
+	target, _ := url.Parse(srv.URL)
+
+	c, _ := NewClient(AndroidClient)
+	c.HTTPClient = &http.Client{Transport: redirect{target}}
+
+	list := make([]string, emails)
+	for i := range list {
+		list[i] = fmt.Sprintf("listener%d@example.com", i)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := c.StationShareStation("123", "456", list); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkCallSmall(b *testing.B) { benchmarkShareStation(b, 1) }
+func BenchmarkCallLarge(b *testing.B) { benchmarkShareStation(b, 1000) }
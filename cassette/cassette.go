@@ -0,0 +1,294 @@
+/*
+Package cassette records the traffic between a gopiano.Client and Pandora
+to a file and replays it later, so tests can run offline against real
+responses.
+
+Request bodies are stored decrypted, and passwords and auth tokens are
+scrubbed from requests, responses and URLs, so cassettes can be read,
+diffed and checked in.
+
+	t, err := cassette.New("testdata/session.json", cassette.Record, gopiano.AndroidClient)
+	client.HTTPClient = &http.Client{Transport: t}
+	...
+	err = t.Save()
+
+When replaying, a request is answered with the first unused recorded
+interaction with the same Pandora method and the same decrypted body,
+ignoring the sync time and auth tokens, which differ between sessions.
+*/
+package cassette
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sync"
+
+	"golang.org/x/crypto/blowfish"
+
+	"denniskupec.com/gopiano"
+	"denniskupec.com/gopiano/coder"
+)
+
+// Mode is what a Transport does with requests.
+type Mode int
+
+const (
+	// Record passes requests on to Pandora and records them.
+	Record Mode = iota
+	// Replay answers requests from a cassette.
+	Replay
+)
+
+// Cassette is the content of a cassette file.
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction is a recorded request and its response.
+type Interaction struct {
+	Method   string          `json:"method"` // Pandora method, e.g. "station.getPlaylist"
+	URL      string          `json:"url"`
+	Request  json.RawMessage `json:"request"` // decrypted request body
+	Status   int             `json:"status"`
+	Response json.RawMessage `json:"response"`
+
+	used bool
+}
+
+// Fields whose values are scrubbed from cassettes. The username is the
+// account's email address in auth.userLogin.
+var secretFields = map[string]bool{
+	"username":         true,
+	"password":         true,
+	"partnerAuthToken": true,
+	"userAuthToken":    true,
+}
+
+// Fields that differ between sessions and are ignored for matching.
+var volatileFields = map[string]bool{
+	"syncTime": true,
+}
+
+// Transport is an http.RoundTripper recording or replaying a cassette.
+type Transport struct {
+	// Next sends requests while recording. If nil, http.DefaultTransport is used.
+	Next http.RoundTripper
+
+	path    string
+	mode    Mode
+	decrypt *blowfish.Cipher
+
+	mu       sync.Mutex
+	cassette Cassette
+}
+
+// New returns a Transport for the cassette file at path. Argument d is the
+// description of the client whose requests are recorded, needed to decrypt
+// them. When replaying, the cassette is read right away.
+func New(path string, mode Mode, d gopiano.ClientDescription) (*Transport, error) {
+	cipher, err := blowfish.NewCipher([]byte(d.EncryptKey))
+	if err != nil {
+		return nil, err
+	}
+
+	t := &Transport{path: path, mode: mode, decrypt: cipher}
+	if mode == Replay {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(data, &t.cassette); err != nil {
+			return nil, fmt.Errorf("%s: %v", path, err)
+		}
+	}
+	return t, nil
+}
+
+// Save writes the recorded interactions to the cassette file.
+func (t *Transport) Save() error {
+	if t.mode != Record {
+		return errors.New("cassette: save while replaying")
+	}
+
+	t.mu.Lock()
+	data, err := json.MarshalIndent(&t.cassette, "", "  ")
+	t.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(t.path, append(data, '\n'), 0644)
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	plain, err := t.plaintext(body)
+	if err != nil {
+		return nil, fmt.Errorf("cassette: %v", err)
+	}
+	method := req.URL.Query().Get("method")
+
+	if t.mode == Replay {
+		return t.replay(req, method, plain)
+	}
+	return t.record(req, method, body, plain)
+}
+
+func (t *Transport) record(req *http.Request, method string, body, plain []byte) (*http.Response, error) {
+	next := t.Next
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	out := req.Clone(req.Context())
+	out.Body = ioutil.NopCloser(bytes.NewReader(body))
+	out.ContentLength = int64(len(body))
+	resp, err := next.RoundTrip(out)
+	if err != nil {
+		return nil, err
+	}
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(respBody))
+
+	scrubbedReq, err := scrub(plain, nil)
+	if err != nil {
+		return nil, fmt.Errorf("cassette: request: %v", err)
+	}
+	scrubbedResp, err := scrub(respBody, nil)
+	if err != nil {
+		return nil, fmt.Errorf("cassette: response: %v", err)
+	}
+
+	t.mu.Lock()
+	t.cassette.Interactions = append(t.cassette.Interactions, Interaction{
+		Method:   method,
+		URL:      gopiano.RedactURL(req.URL.String()),
+		Request:  scrubbedReq,
+		Status:   resp.StatusCode,
+		Response: scrubbedResp,
+	})
+	t.mu.Unlock()
+
+	return resp, nil
+}
+
+func (t *Transport) replay(req *http.Request, method string, plain []byte) (*http.Response, error) {
+	key, err := matchKey(plain)
+	if err != nil {
+		return nil, fmt.Errorf("cassette: request: %v", err)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for i := range t.cassette.Interactions {
+		in := &t.cassette.Interactions[i]
+		if in.used || in.Method != method {
+			continue
+		}
+		if k, err := matchKey(in.Request); err != nil || k != key {
+			continue
+		}
+
+		in.used = true
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", in.Status, http.StatusText(in.Status)),
+			StatusCode:    in.Status,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        http.Header{"Content-Type": {"application/json"}},
+			Body:          ioutil.NopCloser(bytes.NewReader(in.Response)),
+			ContentLength: int64(len(in.Response)),
+			Request:       req,
+		}, nil
+	}
+
+	return nil, fmt.Errorf("cassette: no recorded %s request matching %s", method, key)
+}
+
+// plaintext reverses the encryption of request bodies. The partner login
+// is sent as plain JSON, everything else as hex encoded Blowfish.
+func (t *Transport) plaintext(body []byte) ([]byte, error) {
+	body = bytes.TrimSpace(body)
+	if len(body) == 0 || body[0] == '{' {
+		return body, nil
+	}
+
+	data, err := ioutil.ReadAll(coder.NewDecoder(t.decrypt, bytes.NewReader(body)))
+	if err != nil {
+		return nil, err
+	}
+	return bytes.TrimSpace(data), nil
+}
+
+// scrub replaces the values of secret fields, and removes the fields in
+// drop, anywhere in a JSON document.
+func scrub(data []byte, drop map[string]bool) (json.RawMessage, error) {
+	if len(data) == 0 {
+		return json.RawMessage("null"), nil
+	}
+
+	// Numbers are kept as they are, not converted to float64.
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	v = scrubValue(v, drop)
+	return json.Marshal(v)
+}
+
+func scrubValue(v interface{}, drop map[string]bool) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, x := range v {
+			switch {
+			case drop[k]:
+				delete(v, k)
+			case secretFields[k]:
+				v[k] = gopiano.Redacted
+			default:
+				v[k] = scrubValue(x, drop)
+			}
+		}
+	case []interface{}:
+		for i, x := range v {
+			v[i] = scrubValue(x, drop)
+		}
+	}
+	return v
+}
+
+// matchKey returns the canonical form of a request body used for matching.
+func matchKey(body []byte) (string, error) {
+	drop := make(map[string]bool, len(secretFields)+len(volatileFields))
+	for k := range secretFields {
+		drop[k] = true
+	}
+	for k := range volatileFields {
+		drop[k] = true
+	}
+
+	key, err := scrub(body, drop)
+	return string(key), err
+}
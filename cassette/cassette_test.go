@@ -0,0 +1,119 @@
+package cassette
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/blowfish"
+
+	"denniskupec.com/gopiano"
+)
+
+// fakePandora answers the requests of a short session.
+type fakePandora struct {
+	t      *testing.T
+	cipher *blowfish.Cipher
+}
+
+func (f *fakePandora) RoundTrip(req *http.Request) (*http.Response, error) {
+	var result string
+	switch req.URL.Query().Get("method") {
+	case "auth.partnerLogin":
+		// The sync time is encrypted and follows 4 garbage bytes.
+		syncTime := []byte("abcd1600000000\x00\x00")
+		for i := 0; i < len(syncTime); i += 8 {
+			f.cipher.Encrypt(syncTime[i:i+8], syncTime[i:i+8])
+		}
+		result = `{"partnerId": "42", "partnerAuthToken": "partner-secret", "syncTime": "` + hex.EncodeToString(syncTime) + `"}`
+	case "auth.userLogin":
+		result = `{"userId": "7", "userAuthToken": "user-secret"}`
+	case "track.explainTrack":
+		result = `{"explanations": [{"focusTraitName": "twang"}]}`
+	default:
+		f.t.Errorf("unexpected request %s", req.URL)
+	}
+
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       ioutil.NopCloser(strings.NewReader(`{"stat": "ok", "result": ` + result + `}`)),
+		Request:    req,
+	}, nil
+}
+
+func session(t *testing.T, transport http.RoundTripper, password string) string {
+	c, err := gopiano.NewClient(gopiano.AndroidClient)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.HTTPClient = &http.Client{Transport: transport}
+
+	if _, err := c.AuthPartnerLogin(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.AuthUserLogin("user@example.com", password); err != nil {
+		t.Fatal(err)
+	}
+	resp, err := c.ExplainTrack("track-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp.Explanations[0].FocusTraitName
+}
+
+func TestRecordReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.json")
+
+	rec, err := New(path, Record, gopiano.AndroidClient)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cipher, _ := blowfish.NewCipher([]byte(gopiano.AndroidClient.DecryptKey))
+	rec.Next = &fakePandora{t: t, cipher: cipher}
+
+	if trait := session(t, rec, "password-secret"); trait != "twang" {
+		t.Errorf("unexpected trait %q", trait)
+	}
+	if err := rec.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(data, []byte("secret")) || bytes.Contains(data, []byte("user@example.com")) {
+		t.Errorf("secrets in cassette:\n%s", data)
+	}
+	var c Cassette
+	if err := json.Unmarshal(data, &c); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(c.Interactions); n != 3 {
+		t.Fatalf("expected 3 interactions, got %d", n)
+	}
+	if s := string(c.Interactions[2].Request); !strings.Contains(s, `"trackToken": "track-1"`) {
+		t.Errorf("request not decrypted: %s", s)
+	}
+
+	// Replay with another password, which must not matter.
+	play, err := New(path, Replay, gopiano.AndroidClient)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if trait := session(t, play, "other"); trait != "twang" {
+		t.Errorf("unexpected replayed trait %q", trait)
+	}
+
+	// Every interaction is only replayed once.
+	c2, _ := gopiano.NewClient(gopiano.AndroidClient)
+	c2.HTTPClient = &http.Client{Transport: play}
+	if _, err := c2.ExplainTrack("track-1"); err == nil {
+		t.Error("expected an error for an exhausted cassette")
+	}
+}
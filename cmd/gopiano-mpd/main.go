@@ -0,0 +1,71 @@
+/*
+Command gopiano-mpd serves a Pandora session over the MPD protocol so that
+MPD clients such as ncmpcpp or phone remotes can control it.
+
+Stations are listed as stored playlists; loading one replaces the queue with
+the tracks Pandora picks for it, fetching more as the queue runs out. The
+server keeps time for the current track but does not decode audio itself:
+the file of every song is its audio URL, for outputs that can stream it.
+
+Songs are rated through the "rating" sticker (above 5 is thumbs up, 1 to 4
+thumbs down, 0 or 5 removes the rating) or the extra commands thumbsup,
+thumbsdown, tired and explain, which take an optional song id and default to
+the current song.
+
+Usage:
+
+	gopiano-mpd [-listen localhost:6600] [-device android] -username user@example.com
+
+The password is read from the PANDORA_PASSWORD environment variable
+unless -password is given.
+*/
+package main
+
+import (
+	"flag"
+	"log"
+	"net"
+	"os"
+
+	"denniskupec.com/gopiano"
+)
+
+func main() {
+	listen := flag.String("listen", "localhost:6600", "address to serve MPD clients on")
+	device := flag.String("device", "android", "Pandora client to emulate")
+	username := flag.String("username", os.Getenv("PANDORA_USERNAME"), "Pandora login username")
+	password := flag.String("password", os.Getenv("PANDORA_PASSWORD"), "Pandora login password")
+	flag.Parse()
+
+	desc, ok := gopiano.ClientByName(*device)
+	if !ok {
+		log.Fatalf("unknown device %q", *device)
+	}
+
+	client, err := gopiano.NewClient(desc)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if _, err := client.AuthPartnerLogin(); err != nil {
+		log.Fatal(err)
+	}
+	if _, err := client.AuthUserLogin(*username, *password); err != nil {
+		log.Fatal(err)
+	}
+
+	p := newPlayer(client)
+	if err := p.refreshStations(); err != nil {
+		log.Fatal(err)
+	}
+	if sub, err := client.UserCanSubscribe(); err == nil {
+		p.pref.Subscriber = sub.IsSubscriber
+	}
+
+	l, err := net.Listen("tcp", *listen)
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("serving MPD on %s", l.Addr())
+
+	log.Fatal(serve(l, p))
+}
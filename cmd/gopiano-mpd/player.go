@@ -0,0 +1,604 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"denniskupec.com/gopiano"
+	"denniskupec.com/gopiano/response"
+)
+
+// MPD subsystems reported by idle.
+const (
+	subPlayer         = "player"
+	subPlaylist       = "playlist"
+	subStoredPlaylist = "stored_playlist"
+	subMixer          = "mixer"
+	subSticker        = "sticker"
+	subOptions        = "options"
+	subOutput         = "output"
+	subDatabase       = "database"
+	subUpdate         = "update"
+	subMessage        = "message"
+	subPartition      = "partition"
+	subSubscription   = "subscription"
+	subNeighbor       = "neighbor"
+	subMount          = "mount"
+)
+
+var subsystems = []string{
+	subDatabase, subUpdate, subStoredPlaylist, subPlaylist, subPlayer, subMixer,
+	subOutput, subOptions, subPartition, subSticker, subSubscription, subMessage,
+	subNeighbor, subMount,
+}
+
+type playState int
+
+const (
+	stateStop playState = iota
+	statePlay
+	statePause
+)
+
+func (s playState) String() string {
+	switch s {
+	case statePlay:
+		return "play"
+	case statePause:
+		return "pause"
+	default:
+		return "stop"
+	}
+}
+
+// Ratings as MPD stickers.
+const (
+	ratingUp   = 10
+	ratingDown = 1
+)
+
+type song struct {
+	id           int
+	file         string
+	title        string
+	artist       string
+	album        string
+	station      string
+	stationToken string
+	trackToken   string
+	duration     time.Duration
+	bitrate      int
+	canRate      bool
+}
+
+var (
+	errNoSong     = errors.New("no such song")
+	errNoStation  = errors.New("no such playlist")
+	errNotRatable = errors.New("song cannot be rated")
+)
+
+// player holds the queue and playback state driven by MPD commands.
+// All Pandora calls are made with mu held, so the client is never used concurrently.
+type player struct {
+	client  *gopiano.Client
+	pref    response.AudioPreference
+	ratings *gopiano.Ratings
+
+	mu       sync.Mutex
+	stations response.StationList
+	station  *response.Station
+	queue    []*song
+	cur      int // index into queue of the current song, -1 if none
+	state    playState
+	started  time.Time     // when playback last (re)started
+	elapsed  time.Duration // played before started
+	timer    *time.Timer
+	nextID   int
+	version  int
+	volume   int
+	since    time.Time
+
+	watchers map[chan string]bool
+}
+
+func newPlayer(client *gopiano.Client) *player {
+	return &player{
+		client:   client,
+		pref:     response.DefaultAudioPreference,
+		ratings:  client.Ratings(),
+		cur:      -1,
+		version:  1,
+		volume:   100,
+		since:    time.Now(),
+		watchers: make(map[chan string]bool),
+	}
+}
+
+// watch registers a channel that receives the names of changed subsystems.
+func (p *player) watch(ch chan string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.watchers[ch] = true
+}
+
+func (p *player) unwatch(ch chan string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	delete(p.watchers, ch)
+}
+
+// notify tells watchers about changes. p.mu must be held.
+func (p *player) notify(subs ...string) {
+	for ch := range p.watchers {
+		for _, s := range subs {
+			select {
+			case ch <- s:
+			default: // the connection already has plenty to report
+			}
+		}
+	}
+}
+
+func (p *player) refreshStations() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var resp *response.UserGetStationList
+	err := p.client.WithRelogin(func() (err error) {
+		resp, err = p.client.UserGetStationList(false)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	p.stations = resp.Stations
+	p.notify(subStoredPlaylist)
+	return nil
+}
+
+func (p *player) listStations() response.StationList {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.stations
+}
+
+func (p *player) findStation(name string) *response.Station {
+	for i, s := range p.stations {
+		if strings.EqualFold(s.StationName, name) {
+			return &p.stations[i]
+		}
+	}
+	return nil
+}
+
+// load replaces the queue with tracks from the named station.
+func (p *player) load(name string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	s := p.findStation(name)
+	if s == nil {
+		return errNoStation
+	}
+
+	playing := p.state == statePlay
+	p.stopLocked()
+	p.station = s
+	p.queue = nil
+	p.cur = -1
+	if err := p.fetch(); err != nil {
+		return err
+	}
+	if playing && len(p.queue) > 0 {
+		p.startLocked(0)
+	}
+	return nil
+}
+
+// rename renames a station.
+func (p *player) rename(from, to string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	s := p.findStation(from)
+	if s == nil {
+		return errNoStation
+	}
+
+	err := p.client.WithRelogin(func() error {
+		_, err := p.client.StationRenameStation(s.StationToken, to)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	s.StationName = to
+	p.notify(subStoredPlaylist)
+	return nil
+}
+
+// fetch appends the next tracks of the current station to the queue.
+// p.mu must be held.
+func (p *player) fetch() error {
+	if p.station == nil {
+		return nil
+	}
+
+	var resp *response.StationGetPlaylist
+	err := p.client.WithRelogin(func() (err error) {
+		resp, err = p.client.StationGetPlaylist(p.station.StationToken)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, item := range resp.Items {
+		if item.IsAd() || item.TrackToken == "" {
+			continue
+		}
+
+		p.nextID++
+		s := &song{
+			id:           p.nextID,
+			file:         "pandora:" + item.TrackToken,
+			title:        item.SongName,
+			artist:       item.ArtistName,
+			album:        item.AlbumName,
+			station:      p.station.StationName,
+			stationToken: p.station.StationToken,
+			trackToken:   item.TrackToken,
+			duration:     item.Duration(),
+			canRate:      item.AllowFeedback,
+		}
+		if stream, err := p.pref.Select(item.Streams("")); err == nil {
+			s.file = stream.URL
+			s.bitrate = stream.Bitrate
+		}
+		p.queue = append(p.queue, s)
+	}
+	p.ratings.Track(p.station.StationToken, resp.Items)
+
+	p.version++
+	p.notify(subPlaylist)
+	return nil
+}
+
+func (p *player) current() *song {
+	if p.cur < 0 || p.cur >= len(p.queue) {
+		return nil
+	}
+	return p.queue[p.cur]
+}
+
+func (p *player) position() time.Duration {
+	if p.state == statePlay {
+		return p.elapsed + time.Since(p.started)
+	}
+	return p.elapsed
+}
+
+// startLocked plays the song at pos from its beginning.
+func (p *player) startLocked(pos int) {
+	p.stopTimer()
+	p.cur = pos
+	p.elapsed = 0
+	p.resumeLocked()
+}
+
+func (p *player) resumeLocked() {
+	s := p.current()
+	if s == nil {
+		return
+	}
+
+	p.state = statePlay
+	p.started = time.Now()
+	if s.duration > 0 {
+		id := s.id
+		p.timer = time.AfterFunc(s.duration-p.elapsed, func() { p.ended(id) })
+	}
+	p.notify(subPlayer)
+}
+
+func (p *player) stopTimer() {
+	if p.timer != nil {
+		p.timer.Stop()
+		p.timer = nil
+	}
+}
+
+func (p *player) stopLocked() {
+	p.stopTimer()
+	p.state = stateStop
+	p.elapsed = 0
+	p.notify(subPlayer)
+}
+
+// ended is called when the song with the given id played to its end.
+func (p *player) ended(id int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if s := p.current(); s != nil && s.id == id && p.state == statePlay {
+		p.nextLocked()
+	}
+}
+
+func (p *player) play(pos int) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if pos < 0 {
+		if p.state == statePause {
+			p.resumeLocked()
+			return nil
+		}
+		if p.state == statePlay {
+			return nil
+		}
+		pos = p.cur
+		if pos < 0 {
+			pos = 0
+		}
+	}
+
+	if pos >= len(p.queue) {
+		return errNoSong
+	}
+	p.startLocked(pos)
+	return nil
+}
+
+func (p *player) playID(id int) error {
+	p.mu.Lock()
+	pos := p.indexOf(id)
+	p.mu.Unlock()
+
+	if pos < 0 {
+		return errNoSong
+	}
+	return p.play(pos)
+}
+
+// pause pauses (1), resumes (0) or toggles (-1) playback.
+func (p *player) pause(mode int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	switch {
+	case p.state == statePlay && mode != 0:
+		p.elapsed = p.position()
+		p.stopTimer()
+		p.state = statePause
+		p.notify(subPlayer)
+	case p.state == statePause && mode != 1:
+		p.resumeLocked()
+	}
+}
+
+func (p *player) stop() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.stopLocked()
+}
+
+func (p *player) next() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.nextLocked()
+}
+
+// nextLocked drops the songs up to the current one and plays the next,
+// fetching more from Pandora when the queue runs low.
+func (p *player) nextLocked() error {
+	playing := p.state == statePlay
+	p.stopTimer()
+
+	if p.cur >= 0 {
+		p.queue = p.queue[p.cur+1:]
+		p.version++
+		p.notify(subPlaylist)
+	}
+	p.cur = -1
+
+	var err error
+	if len(p.queue) < 2 {
+		err = p.fetch()
+	}
+
+	if len(p.queue) == 0 {
+		p.stopLocked()
+		return err
+	}
+	p.cur = 0
+	p.elapsed = 0
+	if playing {
+		p.resumeLocked()
+	} else {
+		p.notify(subPlayer)
+	}
+	return err
+}
+
+func (p *player) clear() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.stopLocked()
+	p.queue = nil
+	p.cur = -1
+	p.station = nil
+	p.version++
+	p.notify(subPlaylist)
+}
+
+// remove takes an upcoming song out of the queue.
+func (p *player) remove(id int) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	i := p.indexOf(id)
+	if i < 0 {
+		return errNoSong
+	}
+	if i == p.cur {
+		return errors.New("cannot remove the current song")
+	}
+
+	p.queue = append(p.queue[:i:i], p.queue[i+1:]...)
+	if i < p.cur {
+		p.cur--
+	}
+	p.version++
+	p.notify(subPlaylist)
+	return nil
+}
+
+func (p *player) setVolume(v int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.volume = v
+	p.notify(subMixer)
+}
+
+// indexOf returns the queue position of a song id, or of the current song
+// for id -1. p.mu must be held.
+func (p *player) indexOf(id int) int {
+	if id < 0 {
+		return p.cur
+	}
+	for i, s := range p.queue {
+		if s.id == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func (p *player) song(id int) (*song, error) {
+	i := p.indexOf(id)
+	if i < 0 || i >= len(p.queue) {
+		return nil, errNoSong
+	}
+	return p.queue[i], nil
+}
+
+func (p *player) songByFile(file string) (*song, error) {
+	for _, s := range p.queue {
+		if s.file == file {
+			return s, nil
+		}
+	}
+	return nil, errNoSong
+}
+
+// rating returns the rating of a song.
+func (p *player) rating(s *song) gopiano.Rating {
+	return p.ratings.Rating(s.trackToken)
+}
+
+// rate gives a song a rating, or removes it with gopiano.Unrated.
+// A song banned while playing is skipped.
+func (p *player) rate(s *song, rating gopiano.Rating) error {
+	if !s.canRate {
+		return errNotRatable
+	}
+	if p.rating(s) == rating {
+		return nil
+	}
+
+	err := p.client.WithRelogin(func() error {
+		return p.ratings.Rate(s.stationToken, s.trackToken, rating)
+	})
+	if err != nil {
+		return err
+	}
+	p.notify(subSticker)
+
+	if rating == gopiano.ThumbsDown && s == p.current() {
+		return p.nextLocked()
+	}
+	return nil
+}
+
+func (p *player) rateID(id int, rating gopiano.Rating) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	s, err := p.song(id)
+	if err != nil {
+		return err
+	}
+	return p.rate(s, rating)
+}
+
+func (p *player) rateFile(file string, rating gopiano.Rating) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	s, err := p.songByFile(file)
+	if err != nil {
+		return err
+	}
+	return p.rate(s, rating)
+}
+
+// tired keeps a song from being played for a month and skips it if current.
+func (p *player) tired(id int) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	s, err := p.song(id)
+	if err != nil {
+		return err
+	}
+
+	err = p.client.WithRelogin(func() error {
+		return p.client.UserSleepSong(s.trackToken)
+	})
+	if err != nil {
+		return err
+	}
+
+	if s == p.current() {
+		return p.nextLocked()
+	}
+	return nil
+}
+
+// explain returns the traits Pandora picked a song for.
+func (p *player) explain(id int) ([]string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	s, err := p.song(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp *response.ExplainTrack
+	err = p.client.WithRelogin(func() (err error) {
+		resp, err = p.client.ExplainTrack(s.trackToken)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var traits []string
+	for _, e := range resp.Explanations {
+		traits = append(traits, e.FocusTraitName)
+	}
+	return traits, nil
+}
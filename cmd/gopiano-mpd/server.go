@@ -0,0 +1,786 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"denniskupec.com/gopiano"
+)
+
+const greeting = "OK MPD 0.23.5\n"
+
+// MPD error codes.
+const (
+	ackNotList    = 1
+	ackArg        = 2
+	ackPermission = 4
+	ackUnknown    = 5
+	ackNoExist    = 50
+	ackSystem     = 52
+)
+
+type ackError struct {
+	code int
+	msg  string
+}
+
+func (e *ackError) Error() string {
+	return e.msg
+}
+
+func ack(code int, format string, args ...interface{}) error {
+	return &ackError{code: code, msg: fmt.Sprintf(format, args...)}
+}
+
+type handler func(c *conn, w io.Writer, args []string) error
+
+var handlers map[string]handler
+
+func init() {
+	handlers = map[string]handler{
+		"ping":        func(*conn, io.Writer, []string) error { return nil },
+		"password":    func(*conn, io.Writer, []string) error { return nil },
+		"binarylimit": func(*conn, io.Writer, []string) error { return nil },
+		"status":      cmdStatus,
+		"stats":       cmdStats,
+		"currentsong": cmdCurrentSong,
+
+		"playlistinfo":   cmdPlaylistInfo,
+		"playlistid":     cmdPlaylistID,
+		"plchanges":      cmdPlChanges,
+		"plchangesposid": cmdPlChangesPosID,
+		"clear":          cmdClear,
+		"delete":         cmdDelete,
+		"deleteid":       cmdDeleteID,
+
+		"listplaylists":    cmdListPlaylists,
+		"listplaylist":     cmdListPlaylist,
+		"listplaylistinfo": cmdListPlaylist,
+		"lsinfo":           cmdLsInfo,
+		"load":             cmdLoad,
+		"rename":           cmdRename,
+
+		"play":     cmdPlay,
+		"playid":   cmdPlayID,
+		"pause":    cmdPause,
+		"stop":     cmdStop,
+		"next":     cmdNext,
+		"previous": cmdUnsupported,
+		"seek":     cmdUnsupported,
+		"seekid":   cmdUnsupported,
+		"seekcur":  cmdUnsupported,
+		"add":      cmdUnsupported,
+		"addid":    cmdUnsupported,
+		"rm":       cmdUnsupported,
+		"save":     cmdUnsupported,
+
+		"setvol": cmdSetVol,
+		"getvol": cmdGetVol,
+		"outputs": func(c *conn, w io.Writer, args []string) error {
+			fmt.Fprint(w, "outputid: 0\noutputname: Pandora\nplugin: pandora\noutputenabled: 1\n")
+			return nil
+		},
+		"random":           cmdOption,
+		"repeat":           cmdOption,
+		"single":           cmdOption,
+		"consume":          cmdOption,
+		"crossfade":        cmdOption,
+		"replay_gain_mode": cmdOption,
+		"replay_gain_status": func(c *conn, w io.Writer, args []string) error {
+			fmt.Fprint(w, "replay_gain_mode: track\n")
+			return nil
+		},
+
+		"commands":     cmdCommands,
+		"notcommands":  func(*conn, io.Writer, []string) error { return nil },
+		"tagtypes":     cmdTagTypes,
+		"urlhandlers":  func(*conn, io.Writer, []string) error { return nil },
+		"decoders":     func(*conn, io.Writer, []string) error { return nil },
+		"channels":     func(*conn, io.Writer, []string) error { return nil },
+		"readmessages": func(*conn, io.Writer, []string) error { return nil },
+
+		"sticker":    cmdSticker,
+		"thumbsup":   cmdRate(gopiano.ThumbsUp),
+		"thumbsdown": cmdRate(gopiano.ThumbsDown),
+		"tired":      cmdTired,
+		"explain":    cmdExplain,
+	}
+}
+
+func serve(l net.Listener, p *player) error {
+	for {
+		nc, err := l.Accept()
+		if err != nil {
+			return err
+		}
+		go newConn(nc, p).run()
+	}
+}
+
+type conn struct {
+	nc      net.Conn
+	w       *bufio.Writer
+	p       *player
+	events  chan string
+	changed map[string]bool
+	idle    []string // subsystems waited for, nil if not idling
+}
+
+func newConn(nc net.Conn, p *player) *conn {
+	return &conn{
+		nc:      nc,
+		w:       bufio.NewWriter(nc),
+		p:       p,
+		events:  make(chan string, 64),
+		changed: make(map[string]bool),
+	}
+}
+
+func (c *conn) run() {
+	defer c.nc.Close()
+
+	c.p.watch(c.events)
+	defer c.p.unwatch(c.events)
+
+	// done stops the reader when run returns before the client hung up.
+	lines, done := make(chan string), make(chan struct{})
+	defer close(done)
+	go func() {
+		defer close(lines)
+		s := bufio.NewScanner(c.nc)
+		for s.Scan() {
+			select {
+			case lines <- s.Text():
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	c.w.WriteString(greeting)
+	c.w.Flush()
+
+	var list []string
+	inList, listOK := false, false
+	for {
+		select {
+		case sub := <-c.events:
+			c.changed[sub] = true
+			c.reportIdle()
+
+		case line, ok := <-lines:
+			if !ok {
+				return
+			}
+
+			switch {
+			case c.idle != nil:
+				// Only noidle is allowed while idling.
+				if strings.TrimSpace(line) != "noidle" {
+					return
+				}
+				c.idle = nil
+				c.reportChanges(nil)
+			case inList && line == "command_list_end":
+				c.execList(list, listOK)
+				inList, list = false, nil
+			case inList:
+				list = append(list, line)
+			case line == "command_list_begin" || line == "command_list_ok_begin":
+				inList, listOK = true, line == "command_list_ok_begin"
+			case line == "close":
+				return
+			default:
+				c.execList([]string{line}, false)
+			}
+		}
+		if err := c.w.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// execList runs commands and writes their output, stopping at the first error.
+func (c *conn) execList(lines []string, listOK bool) {
+	for i, line := range lines {
+		args, err := splitArgs(line)
+		if err == nil && len(args) == 0 {
+			err = ack(ackUnknown, "No command given")
+		}
+		if err != nil {
+			fmt.Fprintf(c.w, "ACK [%d@%d] {} %s\n", ackArg, i, err)
+			return
+		}
+
+		name := args[0]
+		if name == "idle" {
+			if len(lines) > 1 {
+				fmt.Fprintf(c.w, "ACK [%d@%d] {idle} idle in command list\n", ackNotList, i)
+				return
+			}
+			c.startIdle(args[1:])
+			return
+		}
+
+		h, ok := handlers[name]
+		if !ok {
+			fmt.Fprintf(c.w, "ACK [%d@%d] {} unknown command \"%s\"\n", ackUnknown, i, name)
+			return
+		}
+		if err := h(c, c.w, args[1:]); err != nil {
+			code := ackSystem
+			if e, ok := err.(*ackError); ok {
+				code = e.code
+			} else if err == errNoSong || err == errNoStation {
+				code = ackNoExist
+			}
+			fmt.Fprintf(c.w, "ACK [%d@%d] {%s} %s\n", code, i, name, err)
+			return
+		}
+		if listOK {
+			c.w.WriteString("list_OK\n")
+		}
+	}
+	c.w.WriteString("OK\n")
+}
+
+func (c *conn) startIdle(subs []string) {
+	if len(subs) == 0 {
+		subs = subsystems
+	}
+	c.idle = subs
+	c.reportIdle()
+}
+
+// reportIdle ends an idle if one of the awaited subsystems changed.
+func (c *conn) reportIdle() {
+	if c.idle == nil {
+		return
+	}
+	for _, s := range c.idle {
+		if c.changed[s] {
+			c.reportChanges(c.idle)
+			c.idle = nil
+			return
+		}
+	}
+}
+
+// reportChanges lists and forgets the changed subsystems out of subs, or all for nil.
+func (c *conn) reportChanges(subs []string) {
+	var names []string
+	for s := range c.changed {
+		if subs == nil || contains(subs, s) {
+			names = append(names, s)
+			delete(c.changed, s)
+		}
+	}
+	sort.Strings(names)
+	for _, s := range names {
+		fmt.Fprintf(c.w, "changed: %s\n", s)
+	}
+	c.w.WriteString("OK\n")
+}
+
+func contains(list []string, s string) bool {
+	for _, x := range list {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
+
+// splitArgs splits a command line into words. Words may be quoted with
+// double quotes, inside which a backslash escapes the next character.
+func splitArgs(line string) ([]string, error) {
+	var args []string
+	for i := 0; i < len(line); {
+		switch line[i] {
+		case ' ', '\t':
+			i++
+		case '"':
+			var arg strings.Builder
+			i++
+			for ; i < len(line) && line[i] != '"'; i++ {
+				if line[i] == '\\' && i+1 < len(line) {
+					i++
+				}
+				arg.WriteByte(line[i])
+			}
+			if i >= len(line) {
+				return nil, fmt.Errorf("missing closing '\"'")
+			}
+			i++
+			args = append(args, arg.String())
+		default:
+			start := i
+			for i < len(line) && line[i] != ' ' && line[i] != '\t' {
+				i++
+			}
+			args = append(args, line[start:i])
+		}
+	}
+	return args, nil
+}
+
+func intArg(args []string, i, def int) (int, error) {
+	if len(args) <= i {
+		return def, nil
+	}
+	n, err := strconv.Atoi(args[i])
+	if err != nil {
+		return 0, ack(ackArg, "Integer expected: %s", args[i])
+	}
+	return n, nil
+}
+
+func cmdStatus(c *conn, w io.Writer, args []string) error {
+	p := c.p
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	fmt.Fprintf(w, "volume: %d\nrepeat: 0\nrandom: 0\nsingle: 0\nconsume: 1\n", p.volume)
+	fmt.Fprintf(w, "playlist: %d\nplaylistlength: %d\nstate: %s\n", p.version, len(p.queue), p.state)
+
+	s := p.current()
+	if s == nil {
+		return nil
+	}
+	fmt.Fprintf(w, "song: %d\nsongid: %d\n", p.cur, s.id)
+	if p.state != stateStop {
+		elapsed := p.position().Seconds()
+		fmt.Fprintf(w, "time: %d:%d\nelapsed: %.3f\n", int(elapsed), int(s.duration.Seconds()), elapsed)
+		if s.duration > 0 {
+			fmt.Fprintf(w, "duration: %.3f\n", s.duration.Seconds())
+		}
+		if s.bitrate > 0 {
+			fmt.Fprintf(w, "bitrate: %d\n", s.bitrate)
+		}
+	}
+	if p.cur+1 < len(p.queue) {
+		fmt.Fprintf(w, "nextsong: %d\nnextsongid: %d\n", p.cur+1, p.queue[p.cur+1].id)
+	}
+	return nil
+}
+
+func cmdStats(c *conn, w io.Writer, args []string) error {
+	c.p.mu.Lock()
+	since := c.p.since
+	c.p.mu.Unlock()
+
+	fmt.Fprintf(w, "artists: 0\nalbums: 0\nsongs: 0\nuptime: %d\nplaytime: 0\ndb_playtime: 0\ndb_update: 0\n",
+		int(time.Since(since).Seconds()))
+	return nil
+}
+
+func writeSong(w io.Writer, s *song, pos int) {
+	fmt.Fprintf(w, "file: %s\n", s.file)
+	if s.artist != "" {
+		fmt.Fprintf(w, "Artist: %s\n", s.artist)
+	}
+	if s.album != "" {
+		fmt.Fprintf(w, "Album: %s\n", s.album)
+	}
+	fmt.Fprintf(w, "Title: %s\nName: %s\n", s.title, s.station)
+	if s.duration > 0 {
+		fmt.Fprintf(w, "Time: %d\nduration: %.3f\n", int(s.duration.Seconds()), s.duration.Seconds())
+	}
+	fmt.Fprintf(w, "Pos: %d\nId: %d\n", pos, s.id)
+}
+
+func cmdCurrentSong(c *conn, w io.Writer, args []string) error {
+	p := c.p
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if s := p.current(); s != nil {
+		writeSong(w, s, p.cur)
+	}
+	return nil
+}
+
+// parseRange parses "POS" or "START:END" with an open END.
+func parseRange(arg string, length int) (start, end int, err error) {
+	parts := strings.SplitN(arg, ":", 2)
+	if start, err = strconv.Atoi(parts[0]); err != nil {
+		return 0, 0, ack(ackArg, "Integer expected: %s", arg)
+	}
+	end = start + 1
+	if len(parts) == 2 {
+		end = length
+		if parts[1] != "" {
+			if end, err = strconv.Atoi(parts[1]); err != nil {
+				return 0, 0, ack(ackArg, "Integer expected: %s", arg)
+			}
+		}
+	}
+	if start < 0 || start > end || (len(parts) == 1 && start >= length) {
+		return 0, 0, ack(ackArg, "Bad song index")
+	}
+	if end > length {
+		end = length
+	}
+	return start, end, nil
+}
+
+func cmdPlaylistInfo(c *conn, w io.Writer, args []string) error {
+	p := c.p
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	start, end := 0, len(p.queue)
+	if len(args) > 0 {
+		var err error
+		if start, end, err = parseRange(args[0], len(p.queue)); err != nil {
+			return err
+		}
+	}
+	for i := start; i < end; i++ {
+		writeSong(w, p.queue[i], i)
+	}
+	return nil
+}
+
+func cmdPlaylistID(c *conn, w io.Writer, args []string) error {
+	id, err := intArg(args, 0, -2)
+	if err != nil {
+		return err
+	}
+
+	p := c.p
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for i, s := range p.queue {
+		if id == -2 || s.id == id {
+			writeSong(w, s, i)
+			if id != -2 {
+				return nil
+			}
+		}
+	}
+	if id != -2 {
+		return errNoSong
+	}
+	return nil
+}
+
+// The queue is never edited in place, so any change lists it all.
+func cmdPlChanges(c *conn, w io.Writer, args []string) error {
+	version, err := intArg(args, 0, 0)
+	if err != nil {
+		return err
+	}
+
+	c.p.mu.Lock()
+	changed := version != c.p.version
+	c.p.mu.Unlock()
+
+	if changed {
+		return cmdPlaylistInfo(c, w, nil)
+	}
+	return nil
+}
+
+func cmdPlChangesPosID(c *conn, w io.Writer, args []string) error {
+	version, err := intArg(args, 0, 0)
+	if err != nil {
+		return err
+	}
+
+	p := c.p
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if version != p.version {
+		for i, s := range p.queue {
+			fmt.Fprintf(w, "cpos: %d\nId: %d\n", i, s.id)
+		}
+	}
+	return nil
+}
+
+func cmdClear(c *conn, w io.Writer, args []string) error {
+	c.p.clear()
+	return nil
+}
+
+func cmdDelete(c *conn, w io.Writer, args []string) error {
+	pos, err := intArg(args, 0, -1)
+	if err != nil {
+		return err
+	}
+
+	p := c.p
+	p.mu.Lock()
+	if pos < 0 || pos >= len(p.queue) {
+		p.mu.Unlock()
+		return ack(ackArg, "Bad song index")
+	}
+	id := p.queue[pos].id
+	p.mu.Unlock()
+
+	return p.remove(id)
+}
+
+func cmdDeleteID(c *conn, w io.Writer, args []string) error {
+	if len(args) == 0 {
+		return ack(ackArg, "too few arguments for \"deleteid\"")
+	}
+	id, err := intArg(args, 0, 0)
+	if err != nil {
+		return err
+	}
+	return c.p.remove(id)
+}
+
+func cmdListPlaylists(c *conn, w io.Writer, args []string) error {
+	for _, s := range c.p.listStations() {
+		if !s.IsQuickMix {
+			fmt.Fprintf(w, "playlist: %s\nLast-Modified: %s\n", s.StationName, s.DateCreated.UTC().Format(time.RFC3339))
+		}
+	}
+	return nil
+}
+
+// Station contents are chosen by Pandora as they are played, so stored
+// playlists look empty.
+func cmdListPlaylist(c *conn, w io.Writer, args []string) error {
+	if len(args) == 0 {
+		return ack(ackArg, "too few arguments")
+	}
+
+	c.p.mu.Lock()
+	defer c.p.mu.Unlock()
+
+	if c.p.findStation(args[0]) == nil {
+		return errNoStation
+	}
+	return nil
+}
+
+func cmdLsInfo(c *conn, w io.Writer, args []string) error {
+	if len(args) > 0 && args[0] != "" && args[0] != "/" {
+		return ack(ackNoExist, "No such directory")
+	}
+	return cmdListPlaylists(c, w, nil)
+}
+
+func cmdLoad(c *conn, w io.Writer, args []string) error {
+	if len(args) == 0 {
+		return ack(ackArg, "too few arguments for \"load\"")
+	}
+	return c.p.load(args[0])
+}
+
+func cmdRename(c *conn, w io.Writer, args []string) error {
+	if len(args) < 2 {
+		return ack(ackArg, "too few arguments for \"rename\"")
+	}
+	return c.p.rename(args[0], args[1])
+}
+
+func cmdPlay(c *conn, w io.Writer, args []string) error {
+	pos, err := intArg(args, 0, -1)
+	if err != nil {
+		return err
+	}
+	return c.p.play(pos)
+}
+
+func cmdPlayID(c *conn, w io.Writer, args []string) error {
+	id, err := intArg(args, 0, -1)
+	if err != nil {
+		return err
+	}
+	if id < 0 {
+		return c.p.play(-1)
+	}
+	return c.p.playID(id)
+}
+
+func cmdPause(c *conn, w io.Writer, args []string) error {
+	mode, err := intArg(args, 0, -1)
+	if err != nil {
+		return err
+	}
+	c.p.pause(mode)
+	return nil
+}
+
+func cmdStop(c *conn, w io.Writer, args []string) error {
+	c.p.stop()
+	return nil
+}
+
+func cmdNext(c *conn, w io.Writer, args []string) error {
+	return c.p.next()
+}
+
+func cmdUnsupported(c *conn, w io.Writer, args []string) error {
+	return ack(ackPermission, "not possible with Pandora")
+}
+
+func cmdSetVol(c *conn, w io.Writer, args []string) error {
+	v, err := intArg(args, 0, -1)
+	if err != nil {
+		return err
+	}
+	if v < 0 || v > 100 {
+		return ack(ackArg, "Invalid volume value")
+	}
+	c.p.setVolume(v)
+	return nil
+}
+
+func cmdGetVol(c *conn, w io.Writer, args []string) error {
+	c.p.mu.Lock()
+	defer c.p.mu.Unlock()
+
+	fmt.Fprintf(w, "volume: %d\n", c.p.volume)
+	return nil
+}
+
+// Playback options are fixed; changing them is accepted and ignored.
+func cmdOption(c *conn, w io.Writer, args []string) error {
+	return nil
+}
+
+func cmdCommands(c *conn, w io.Writer, args []string) error {
+	names := []string{"close", "command_list_begin", "command_list_ok_begin", "command_list_end", "idle", "noidle"}
+	for name := range handlers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "command: %s\n", name)
+	}
+	return nil
+}
+
+func cmdTagTypes(c *conn, w io.Writer, args []string) error {
+	if len(args) == 0 {
+		fmt.Fprint(w, "tagtype: Artist\ntagtype: Album\ntagtype: Title\ntagtype: Name\n")
+	}
+	return nil
+}
+
+func ratingSticker(rating gopiano.Rating) int {
+	switch rating {
+	case gopiano.ThumbsUp:
+		return ratingUp
+	case gopiano.ThumbsDown:
+		return ratingDown
+	}
+	return 0
+}
+
+// cmdSticker maps the "rating" sticker of songs to Pandora feedback.
+func cmdSticker(c *conn, w io.Writer, args []string) error {
+	if len(args) < 3 || args[1] != "song" {
+		return ack(ackArg, "only song stickers are supported")
+	}
+	sub, uri := args[0], args[2]
+	name := "rating"
+	if len(args) > 3 {
+		name = args[3]
+	}
+	if name != "rating" {
+		return ack(ackNoExist, "no such sticker")
+	}
+
+	p := c.p
+	switch sub {
+	case "get", "list":
+		p.mu.Lock()
+		defer p.mu.Unlock()
+
+		s, err := p.songByFile(uri)
+		if err != nil {
+			return err
+		}
+		rating := p.rating(s)
+		if rating == gopiano.Unrated {
+			if sub == "list" {
+				return nil
+			}
+			return ack(ackNoExist, "no such sticker")
+		}
+		fmt.Fprintf(w, "sticker: rating=%d\n", ratingSticker(rating))
+		return nil
+
+	case "find":
+		p.mu.Lock()
+		defer p.mu.Unlock()
+
+		for _, s := range p.queue {
+			if rating := p.rating(s); rating != gopiano.Unrated {
+				fmt.Fprintf(w, "file: %s\nsticker: rating=%d\n", s.file, ratingSticker(rating))
+			}
+		}
+		return nil
+
+	case "set":
+		if len(args) < 5 {
+			return ack(ackArg, "too few arguments for \"sticker set\"")
+		}
+		v, err := strconv.Atoi(args[4])
+		if err != nil {
+			return ack(ackArg, "Integer expected: %s", args[4])
+		}
+		rating := gopiano.Unrated
+		switch {
+		case v > 5:
+			rating = gopiano.ThumbsUp
+		case v > 0 && v < 5:
+			rating = gopiano.ThumbsDown
+		}
+		return p.rateFile(uri, rating)
+
+	case "delete":
+		return p.rateFile(uri, gopiano.Unrated)
+	}
+
+	return ack(ackArg, "bad request")
+}
+
+func cmdRate(rating gopiano.Rating) handler {
+	return func(c *conn, w io.Writer, args []string) error {
+		id, err := intArg(args, 0, -1)
+		if err != nil {
+			return err
+		}
+		return c.p.rateID(id, rating)
+	}
+}
+
+func cmdTired(c *conn, w io.Writer, args []string) error {
+	id, err := intArg(args, 0, -1)
+	if err != nil {
+		return err
+	}
+	return c.p.tired(id)
+}
+
+func cmdExplain(c *conn, w io.Writer, args []string) error {
+	id, err := intArg(args, 0, -1)
+	if err != nil {
+		return err
+	}
+
+	traits, err := c.p.explain(id)
+	if err != nil {
+		return err
+	}
+	for _, t := range traits {
+		fmt.Fprintf(w, "trait: %s\n", t)
+	}
+	return nil
+}
@@ -0,0 +1,299 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"denniskupec.com/gopiano"
+	"denniskupec.com/gopiano/request"
+	"denniskupec.com/gopiano/response"
+)
+
+func TestSplitArgs(t *testing.T) {
+	data := []struct {
+		Line string
+		Args []string
+	}{
+		{"status", []string{"status"}},
+		{"  play   3 ", []string{"play", "3"}},
+		{`load "My \"Rock\" Radio"`, []string{"load", `My "Rock" Radio`}},
+		{`rename "a b" c\d`, []string{"rename", "a b", `c\d`}},
+		{`sticker set song "" rating 10`, []string{"sticker", "set", "song", "", "rating", "10"}},
+	}
+
+	for _, d := range data {
+		args, err := splitArgs(d.Line)
+		if err != nil {
+			t.Errorf("%q: %v", d.Line, err)
+		} else if !reflect.DeepEqual(args, d.Args) {
+			t.Errorf("\nexpected:\n\t%q\ngot:\n\t%q", d.Args, args)
+		}
+	}
+
+	if _, err := splitArgs(`load "unterminated`); err == nil {
+		t.Error("expected an error for a missing quote")
+	}
+}
+
+func TestParseRange(t *testing.T) {
+	data := []struct {
+		Arg        string
+		Start, End int
+		Err        bool
+	}{
+		{"0", 0, 1, false},
+		{"1:3", 1, 3, false},
+		{"1:", 1, 4, false},
+		{"2:10", 2, 4, false},
+		{"4", 0, 0, true},
+		{"x", 0, 0, true},
+	}
+
+	for _, d := range data {
+		start, end, err := parseRange(d.Arg, 4)
+		if (err != nil) != d.Err {
+			t.Errorf("%q: unexpected error %v", d.Arg, err)
+		} else if !d.Err && (start != d.Start || end != d.End) {
+			t.Errorf("%q: expected %d:%d, got %d:%d", d.Arg, d.Start, d.End, start, end)
+		}
+	}
+}
+
+// fakePandora answers the calls of the player for a station "Jazz" whose
+// playlists have two tracks.
+type fakePandora struct {
+	mu         sync.Mutex
+	calls      []string
+	failDelete bool
+	tracks     int
+}
+
+func (f *fakePandora) intercept(req request.Type, data interface{}, next gopiano.Invoker) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch r := req.(type) {
+	case request.GetStationList:
+		data.(*response.UserGetStationList).Stations = response.StationList{
+			{StationToken: "st", StationName: "Jazz"},
+		}
+	case request.GetPlaylist:
+		resp := data.(*response.StationGetPlaylist)
+		for i := 0; i < 2; i++ {
+			f.tracks++
+			n := string(rune('0' + f.tracks))
+			resp.Items = append(resp.Items, response.PlaylistItem{
+				TrackToken:    "t" + n,
+				SongName:      "Song " + n,
+				ArtistName:    "Artist",
+				AllowFeedback: true,
+				TrackLength:   300,
+				AudioURLMap: map[string]response.AudioStream{
+					response.HighQuality: {Bitrate: "64", Encoding: "aacplus", AudioURL: "http://audio/" + n},
+				},
+			})
+		}
+	case request.AddFeedback:
+		f.calls = append(f.calls, "add "+r.StationToken+" "+r.TrackToken)
+		data.(*response.StationAddFeedback).FeedbackID = "f-" + r.TrackToken
+	case request.DeleteFeedback:
+		f.calls = append(f.calls, "delete "+r.FeedbackID)
+		if f.failDelete {
+			return errors.New("delete failed")
+		}
+	}
+	return nil
+}
+
+func newTestPlayer(t *testing.T) (*player, *fakePandora) {
+	f := &fakePandora{}
+	client, _ := gopiano.NewClient(gopiano.AndroidClient)
+	client.Interceptors = []gopiano.Interceptor{f.intercept}
+
+	p := newPlayer(client)
+	if err := p.refreshStations(); err != nil {
+		t.Fatal(err)
+	}
+	return p, f
+}
+
+// testConn is the client side of a connection to the server.
+type testConn struct {
+	t  *testing.T
+	nc net.Conn
+	r  *bufio.Reader
+}
+
+func dial(t *testing.T, p *player) *testConn {
+	client, server := net.Pipe()
+	go newConn(server, p).run()
+
+	c := &testConn{t: t, nc: client, r: bufio.NewReader(client)}
+	if greeting := c.response(); !reflect.DeepEqual(greeting, []string{"OK MPD 0.23.5"}) {
+		t.Fatalf("unexpected greeting %q", greeting)
+	}
+	return c
+}
+
+func (c *testConn) send(lines ...string) {
+	c.t.Helper()
+	c.nc.SetWriteDeadline(time.Now().Add(time.Second))
+	if _, err := c.nc.Write([]byte(strings.Join(lines, "\n") + "\n")); err != nil {
+		c.t.Fatal(err)
+	}
+}
+
+// response reads lines up to and including OK or an ACK.
+func (c *testConn) response() []string {
+	c.t.Helper()
+	c.nc.SetReadDeadline(time.Now().Add(time.Second))
+
+	var lines []string
+	for {
+		line, err := c.r.ReadString('\n')
+		if err != nil {
+			c.t.Fatalf("reading response after %q: %v", lines, err)
+		}
+		line = strings.TrimSuffix(line, "\n")
+		lines = append(lines, line)
+		if strings.HasPrefix(line, "OK") || strings.HasPrefix(line, "ACK") {
+			return lines
+		}
+	}
+}
+
+// silent checks that nothing is sent for a while.
+func (c *testConn) silent() {
+	c.t.Helper()
+	c.nc.SetReadDeadline(time.Now().Add(50 * time.Millisecond))
+	if line, err := c.r.ReadString('\n'); err == nil {
+		c.t.Errorf("unexpected %q", line)
+	}
+}
+
+// expect sends a command and checks the response.
+func (c *testConn) expect(cmd string, expected ...string) {
+	c.t.Helper()
+	c.send(cmd)
+	c.receive(expected...)
+}
+
+func (c *testConn) receive(expected ...string) {
+	c.t.Helper()
+	if got := c.response(); !reflect.DeepEqual(got, expected) {
+		c.t.Errorf("\nexpected:\n\t%q\ngot:\n\t%q", expected, got)
+	}
+}
+
+func TestIdle(t *testing.T) {
+	p, _ := newTestPlayer(t)
+	a, b := dial(t, p), dial(t, p)
+	defer a.nc.Close()
+	defer b.nc.Close()
+
+	a.send("idle playlist")
+	a.silent()
+
+	// Changes of other subsystems do not end the idle.
+	b.expect("setvol 50", "OK")
+	a.silent()
+
+	b.expect("load Jazz", "OK")
+	a.receive("changed: playlist", "OK")
+
+	// The other changes are still pending and end the next idle right away.
+	a.expect("idle", "changed: mixer", "changed: player", "OK")
+
+	a.send("idle")
+	a.silent()
+	a.expect("noidle", "OK")
+
+	a.expect("command_list_begin\nping\nidle\ncommand_list_end", "ACK [1@1] {idle} idle in command list")
+}
+
+func TestIdleDisconnect(t *testing.T) {
+	p, _ := newTestPlayer(t)
+	before := runtime.NumGoroutine()
+
+	c := dial(t, p)
+	c.send("idle")
+	c.silent()
+
+	// Anything but noidle while idling ends the connection, even with more
+	// lines already read.
+	c.send("status", "ping")
+	c.nc.SetReadDeadline(time.Now().Add(time.Second))
+	if line, err := c.r.ReadString('\n'); err == nil {
+		t.Errorf("expected the connection to be closed, got %q", line)
+	}
+	c.nc.Close()
+
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > before {
+		if time.Now().After(deadline) {
+			t.Fatalf("connection goroutines leaked: %d, was %d", runtime.NumGoroutine(), before)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestPlaylistCommands(t *testing.T) {
+	p, _ := newTestPlayer(t)
+	c := dial(t, p)
+	defer c.nc.Close()
+
+	c.expect("listplaylists", "playlist: Jazz", "Last-Modified: 0001-01-01T00:00:00Z", "OK")
+	c.expect("load Rock", "ACK [50@0] {load} no such playlist")
+	c.expect("load Jazz", "OK")
+
+	song := func(n, pos string) []string {
+		return []string{"file: http://audio/" + n, "Artist: Artist", "Title: Song " + n, "Name: Jazz",
+			"Time: 300", "duration: 300.000", "Pos: " + pos, "Id: " + n}
+	}
+	c.expect("playlistinfo", append(append(song("1", "0"), song("2", "1")...), "OK")...)
+	c.expect("playlistid 2", append(song("2", "1"), "OK")...)
+	c.expect("playlistid 9", "ACK [50@0] {playlistid} no such song")
+	c.expect("playlistinfo 5", "ACK [2@0] {playlistinfo} Bad song index")
+
+	c.expect("plchangesposid 0", "cpos: 0", "Id: 1", "cpos: 1", "Id: 2", "OK")
+	c.expect("deleteid 1", "OK")
+	c.expect("playlistinfo", append(song("2", "0"), "OK")...)
+
+	c.expect("command_list_ok_begin\nclear\nplaylistinfo\ncommand_list_end", "list_OK", "list_OK", "OK")
+}
+
+func TestRate(t *testing.T) {
+	p, f := newTestPlayer(t)
+	c := dial(t, p)
+	defer c.nc.Close()
+
+	c.expect("load Jazz", "OK")
+	c.expect("thumbsup 1", "OK")
+	c.expect("sticker get song http://audio/1 rating", "sticker: rating=10", "OK")
+	c.expect("sticker get song http://audio/2 rating", "ACK [50@0] {sticker} no such sticker")
+
+	// A failed removal keeps the rating, so it can be removed later.
+	f.failDelete = true
+	c.expect("sticker delete song http://audio/1 rating", "ACK [52@0] {sticker} delete failed")
+	c.expect("sticker get song http://audio/1 rating", "sticker: rating=10", "OK")
+
+	f.failDelete = false
+	c.expect("sticker delete song http://audio/1 rating", "OK")
+	c.expect("sticker get song http://audio/1 rating", "ACK [50@0] {sticker} no such sticker")
+	c.expect("sticker delete song http://audio/1 rating", "OK")
+
+	c.expect("sticker set song http://audio/2 rating 1", "OK")
+	c.expect("sticker find song \"\" rating", "file: http://audio/2", "sticker: rating=1", "OK")
+
+	expected := []string{"add st t1", "delete f-t1", "delete f-t1", "add st t2"}
+	if !reflect.DeepEqual(f.calls, expected) {
+		t.Errorf("\nexpected:\n\t%q\ngot:\n\t%q", expected, f.calls)
+	}
+}
@@ -0,0 +1,63 @@
+package main
+
+import "io"
+
+// icyMetaInt is the number of audio bytes between metadata blocks.
+const icyMetaInt = 16000
+
+// icyWriter interleaves SHOUTcast metadata with the audio written to it.
+type icyWriter struct {
+	w       io.Writer
+	metaInt int
+	left    int    // audio bytes until the next metadata block
+	title   string // current StreamTitle
+	sent    string // last StreamTitle sent
+}
+
+func newICYWriter(w io.Writer, metaInt int) *icyWriter {
+	return &icyWriter{w: w, metaInt: metaInt, left: metaInt}
+}
+
+func (iw *icyWriter) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		n := len(p)
+		if n > iw.left {
+			n = iw.left
+		}
+		if _, err := iw.w.Write(p[:n]); err != nil {
+			return written, err
+		}
+		written += n
+		p = p[n:]
+
+		iw.left -= n
+		if iw.left == 0 {
+			if _, err := iw.w.Write(iw.metadata()); err != nil {
+				return written, err
+			}
+			iw.left = iw.metaInt
+		}
+	}
+	return written, nil
+}
+
+// metadata returns the next metadata block: a length byte counting 16 byte
+// units followed by the zero padded text, or just a zero length byte if the
+// title did not change.
+func (iw *icyWriter) metadata() []byte {
+	if iw.title == iw.sent {
+		return []byte{0}
+	}
+	iw.sent = iw.title
+
+	meta := "StreamTitle='" + iw.title + "';"
+	if len(meta) > 255*16 {
+		meta = meta[:255*16-2] + "';"
+	}
+	n := (len(meta) + 15) / 16
+	b := make([]byte, 1+n*16)
+	b[0] = byte(n)
+	copy(b[1:], meta)
+	return b
+}
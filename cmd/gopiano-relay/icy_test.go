@@ -0,0 +1,27 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestICYWriter(t *testing.T) {
+	var buf bytes.Buffer
+	iw := newICYWriter(&buf, 4)
+	iw.title = "Artist - Song"
+
+	iw.Write([]byte("abcdef"))
+	iw.Write([]byte("gh"))
+	iw.title = "Other - Song"
+	iw.Write([]byte("ijkl"))
+
+	meta := "StreamTitle='Artist - Song';"
+	meta1 := "\x02" + meta + string(make([]byte, 32-len(meta)))
+	meta = "StreamTitle='Other - Song';"
+	meta2 := "\x02" + meta + string(make([]byte, 32-len(meta)))
+
+	expected := "abcd" + meta1 + "efgh" + "\x00" + "ijkl" + meta2
+	if got := buf.String(); got != expected {
+		t.Errorf("\nexpected:\n\t%q\ngot:\n\t%q", expected, got)
+	}
+}
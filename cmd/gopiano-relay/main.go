@@ -0,0 +1,79 @@
+/*
+Command gopiano-relay plays a Pandora station forever as one continuous MP3
+stream over HTTP, e.g. for speakers or internet radio players.
+
+Tracks are fetched from the station as they are needed and relayed at their
+own bitrate, so every listener hears the same thing at the same time over a
+single Pandora session. Players asking for ICY metadata (Icy-MetaData: 1)
+get the current "Artist - Song" as StreamTitle. Listeners that cannot keep
+up lose audio rather than holding back the others, and no tracks are fetched
+while nobody listens.
+
+Usage:
+
+	gopiano-relay [-listen :8000] [-device android] -username user@example.com -station "Jazz Radio"
+
+The password is read from the PANDORA_PASSWORD environment variable
+unless -password is given.
+*/
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+
+	"denniskupec.com/gopiano"
+	"denniskupec.com/gopiano/response"
+)
+
+func main() {
+	listen := flag.String("listen", ":8000", "address to serve the stream on")
+	device := flag.String("device", "android", "Pandora client to emulate")
+	username := flag.String("username", os.Getenv("PANDORA_USERNAME"), "Pandora login username")
+	password := flag.String("password", os.Getenv("PANDORA_PASSWORD"), "Pandora login password")
+	stationName := flag.String("station", "", "name of the station to play")
+	flag.Parse()
+
+	if *stationName == "" {
+		log.Fatal("no -station given")
+	}
+	desc, ok := gopiano.ClientByName(*device)
+	if !ok {
+		log.Fatalf("unknown device %q", *device)
+	}
+
+	client, err := gopiano.NewClient(desc)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if _, err := client.AuthPartnerLogin(); err != nil {
+		log.Fatal(err)
+	}
+	if _, err := client.AuthUserLogin(*username, *password); err != nil {
+		log.Fatal(err)
+	}
+
+	list, err := client.UserGetStationList(false)
+	if err != nil {
+		log.Fatal(err)
+	}
+	var station *response.Station
+	for i, s := range list.Stations {
+		if strings.EqualFold(s.StationName, *stationName) {
+			station = &list.Stations[i]
+			break
+		}
+	}
+	if station == nil {
+		log.Fatalf("no station named %q", *stationName)
+	}
+
+	r := newRelay(client, station)
+	go r.run()
+
+	log.Printf("relaying %q on %s", station.StationName, *listen)
+	log.Fatal(http.ListenAndServe(*listen, r))
+}
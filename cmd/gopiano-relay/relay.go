@@ -0,0 +1,225 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"denniskupec.com/gopiano"
+	"denniskupec.com/gopiano/response"
+)
+
+// audioType is the additional stream requested with every playlist.
+const audioType = "HTTP_128_MP3"
+
+var pref = response.AudioPreference{
+	Encodings: []string{"mp3"},
+}
+
+const (
+	// Audio sent to listeners per chunk.
+	chunkDuration = 100 * time.Millisecond
+	// Chunks buffered for each listener before it starts losing audio.
+	listenerBuffer = 50
+	// How far the relay may run ahead of real time.
+	lead = time.Second
+	// Bitrate assumed for streams that do not say, in kbit/s.
+	defaultBitrate = 128
+	// Pause after a failed playlist fetch.
+	retryDelay = 10 * time.Second
+)
+
+type chunk struct {
+	data  []byte
+	title string
+}
+
+type relay struct {
+	client     *gopiano.Client
+	station    *response.Station
+	httpClient *http.Client
+
+	mu        sync.Mutex
+	joined    *sync.Cond // signalled when a listener joins
+	listeners map[chan chunk]bool
+}
+
+func newRelay(client *gopiano.Client, station *response.Station) *relay {
+	r := &relay{
+		client:     client,
+		station:    station,
+		httpClient: http.DefaultClient,
+		listeners:  make(map[chan chunk]bool),
+	}
+	r.joined = sync.NewCond(&r.mu)
+	return r
+}
+
+// run relays tracks of the station forever.
+func (r *relay) run() {
+	var p pacer
+	for {
+		r.waitListeners()
+
+		var playlist *response.StationGetPlaylist
+		err := r.client.WithRelogin(func() (err error) {
+			playlist, err = r.client.StationGetPlaylistAudio(r.station.StationToken, audioType)
+			return err
+		})
+		if err != nil {
+			log.Printf("fetching playlist: %v", err)
+			time.Sleep(retryDelay)
+			continue
+		}
+
+		for _, item := range playlist.Items {
+			if item.IsAd() {
+				continue
+			}
+			r.waitListeners()
+
+			s, err := pref.Select(item.Streams(audioType))
+			if err != nil {
+				log.Printf("%s - %s: %v", item.ArtistName, item.SongName, err)
+				continue
+			}
+
+			title := item.ArtistName + " - " + item.SongName
+			if err := r.play(s, title, &p); err != nil {
+				log.Printf("%s: %v", title, err)
+			}
+		}
+	}
+}
+
+// waitListeners blocks until somebody listens.
+func (r *relay) waitListeners() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for len(r.listeners) == 0 {
+		r.joined.Wait()
+	}
+}
+
+// play downloads a stream and hands it to the listeners at its bitrate.
+func (r *relay) play(s response.Stream, title string, p *pacer) error {
+	resp, err := r.httpClient.Get(s.URL)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("audio download: %s", resp.Status)
+	}
+
+	bitrate := s.Bitrate
+	if bitrate <= 0 {
+		bitrate = defaultBitrate
+	}
+	size := bitrate * 1000 / 8 * int(chunkDuration/time.Millisecond) / 1000
+
+	for {
+		buf := make([]byte, size)
+		n, err := io.ReadFull(resp.Body, buf)
+		if n > 0 {
+			p.wait(time.Duration(n) * 8 * time.Second / time.Duration(bitrate*1000))
+			r.broadcast(chunk{data: buf[:n], title: title})
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (r *relay) broadcast(c chunk) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for ch := range r.listeners {
+		select {
+		case ch <- c:
+		default:
+			// The listener fell behind; it loses this chunk.
+		}
+	}
+}
+
+func (r *relay) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != "GET" && req.Method != "HEAD" {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	h := w.Header()
+	h.Set("Content-Type", "audio/mpeg")
+	h.Set("Cache-Control", "no-cache, no-store")
+	h.Set("icy-name", r.station.StationName)
+
+	var out io.Writer = w
+	var icy *icyWriter
+	if req.Header.Get("Icy-MetaData") == "1" {
+		icy = newICYWriter(w, icyMetaInt)
+		out = icy
+		h.Set("icy-metaint", strconv.Itoa(icyMetaInt))
+	}
+	w.WriteHeader(http.StatusOK)
+	if req.Method == "HEAD" {
+		return
+	}
+
+	ch := make(chan chunk, listenerBuffer)
+	r.mu.Lock()
+	r.listeners[ch] = true
+	r.joined.Broadcast()
+	r.mu.Unlock()
+
+	defer func() {
+		r.mu.Lock()
+		delete(r.listeners, ch)
+		r.mu.Unlock()
+	}()
+
+	flusher, _ := w.(http.Flusher)
+	for {
+		select {
+		case c := <-ch:
+			if icy != nil {
+				icy.title = c.title
+			}
+			if _, err := out.Write(c.data); err != nil {
+				return
+			}
+			if flusher != nil {
+				flusher.Flush()
+			}
+		case <-req.Context().Done():
+			return
+		}
+	}
+}
+
+// pacer keeps the relay close to real time.
+type pacer struct {
+	next time.Time // when the audio sent so far has been played
+}
+
+// wait accounts for d more audio, sleeping if that gets too far ahead.
+func (p *pacer) wait(d time.Duration) {
+	now := time.Now()
+	if p.next.Before(now) {
+		// Fell behind, e.g. after a slow download; do not rush to catch up.
+		p.next = now
+	}
+	p.next = p.next.Add(d)
+	if ahead := p.next.Sub(now) - lead; ahead > 0 {
+		time.Sleep(ahead)
+	}
+}
@@ -0,0 +1,132 @@
+/*
+Command gopiano-server exposes a Pandora account over a small HTTP/JSON API,
+so that dashboards and other internal tools can use it without knowing the
+Pandora credentials.
+
+Every request must carry one of the configured API keys, either as
+"Authorization: Bearer KEY" or in an "X-API-Key" header. Each key is granted
+a set of permissions:
+
+	read      list stations, station details, search, bookmarks, explain
+	listen    fetch playlists
+	rate      add and delete feedback, put tracks to sleep
+	bookmark  add bookmarks
+	manage    create, rename and delete stations
+	*         all of the above
+
+Endpoints, all answering with the JSON of the matching response type:
+
+	GET    /stations                    read
+	POST   /stations                    manage    {"musicToken"} or {"trackToken", "musicType"}
+	GET    /stations/TOKEN              read
+	PATCH  /stations/TOKEN              manage    {"name"}
+	DELETE /stations/TOKEN              manage
+	GET    /stations/TOKEN/playlist     listen
+	POST   /feedback                    rate      {"stationToken", "trackToken", "isPositive"}
+	DELETE /feedback/ID                 rate
+	GET    /tracks/TOKEN/explain        read
+	POST   /tracks/TOKEN/sleep          rate
+	GET    /search?q=TEXT               read
+	GET    /bookmarks                   read
+	POST   /bookmarks                   bookmark  {"trackToken", "type": "song" or "artist"}
+
+Errors are answered as {"error": "...", "code": N}, where code is the
+Pandora error code if there is one.
+
+Usage:
+
+	gopiano-server [-listen localhost:8080] -config gopiano-server.json
+
+The configuration file looks like
+
+	{
+		"device": "android",
+		"username": "user@example.com",
+		"password": "secret",
+		"keys": [
+			{"name": "dashboard", "key": "long random string", "permissions": ["read"]}
+		]
+	}
+
+The password may be left out and given in the PANDORA_PASSWORD environment
+variable instead.
+*/
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+
+	"denniskupec.com/gopiano"
+)
+
+type config struct {
+	Device   string   `json:"device"`
+	Username string   `json:"username"`
+	Password string   `json:"password"`
+	Keys     []apiKey `json:"keys"`
+}
+
+func loadConfig(name string) (*config, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	cfg := config{Device: "android"}
+	if err := json.NewDecoder(f).Decode(&cfg); err != nil {
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+	if cfg.Password == "" {
+		cfg.Password = os.Getenv("PANDORA_PASSWORD")
+	}
+
+	if cfg.Username == "" {
+		return nil, fmt.Errorf("%s: no username", name)
+	}
+	if len(cfg.Keys) == 0 {
+		return nil, fmt.Errorf("%s: no API keys", name)
+	}
+	for _, k := range cfg.Keys {
+		if err := k.check(); err != nil {
+			return nil, fmt.Errorf("%s: %v", name, err)
+		}
+	}
+
+	return &cfg, nil
+}
+
+func main() {
+	listen := flag.String("listen", "localhost:8080", "address to serve HTTP on")
+	configFile := flag.String("config", "gopiano-server.json", "configuration file")
+	flag.Parse()
+
+	cfg, err := loadConfig(*configFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	desc, ok := gopiano.ClientByName(cfg.Device)
+	if !ok {
+		log.Fatalf("unknown device %q", cfg.Device)
+	}
+
+	client, err := gopiano.NewClient(desc)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if _, err := client.AuthPartnerLogin(); err != nil {
+		log.Fatal(err)
+	}
+	if _, err := client.AuthUserLogin(cfg.Username, cfg.Password); err != nil {
+		log.Fatal(err)
+	}
+
+	log.Printf("serving on %s", *listen)
+	log.Fatal(http.ListenAndServe(*listen, newServer(client, cfg.Keys)))
+}
@@ -0,0 +1,427 @@
+package main
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+
+	"denniskupec.com/gopiano"
+	"denniskupec.com/gopiano/response"
+)
+
+// Permissions that can be granted to an API key.
+const (
+	permRead     = "read"
+	permListen   = "listen"
+	permRate     = "rate"
+	permBookmark = "bookmark"
+	permManage   = "manage"
+	permAll      = "*"
+)
+
+var permissions = []string{permRead, permListen, permRate, permBookmark, permManage, permAll}
+
+// Largest request body accepted.
+const maxBody = 1 << 20
+
+type apiKey struct {
+	Name        string   `json:"name"`
+	Key         string   `json:"key"`
+	Permissions []string `json:"permissions"`
+}
+
+func (k *apiKey) check() error {
+	if k.Key == "" {
+		return fmt.Errorf("key %q is empty", k.Name)
+	}
+	for _, p := range k.Permissions {
+		if !contains(permissions, p) {
+			return fmt.Errorf("key %q: unknown permission %q", k.Name, p)
+		}
+	}
+	return nil
+}
+
+func (k *apiKey) can(perm string) bool {
+	return contains(k.Permissions, perm) || contains(k.Permissions, permAll)
+}
+
+func contains(list []string, s string) bool {
+	for _, x := range list {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
+
+// httpError is an error with the HTTP status to answer it with.
+type httpError struct {
+	status int
+	msg    string
+}
+
+func (e *httpError) Error() string {
+	return e.msg
+}
+
+var (
+	errUnauthorized = &httpError{http.StatusUnauthorized, "missing or unknown API key"}
+	errForbidden    = &httpError{http.StatusForbidden, "API key lacks permission"}
+	errNotFound     = &httpError{http.StatusNotFound, "not found"}
+	errMethod       = &httpError{http.StatusMethodNotAllowed, "method not allowed"}
+)
+
+func badRequest(format string, args ...interface{}) error {
+	return &httpError{http.StatusBadRequest, fmt.Sprintf(format, args...)}
+}
+
+// A handler answers a request with a value to encode as JSON, or nil for
+// no content. Path parameters are passed in the order of the pattern.
+type handler func(s *server, r *http.Request, params []string) (interface{}, error)
+
+type route struct {
+	method  string
+	pattern []string // path segments, "*" matches any one segment
+	perm    string
+	handle  handler
+}
+
+var routes = []route{
+	{"GET", []string{"stations"}, permRead, getStations},
+	{"POST", []string{"stations"}, permManage, createStation},
+	{"GET", []string{"stations", "*"}, permRead, getStation},
+	{"PATCH", []string{"stations", "*"}, permManage, renameStation},
+	{"DELETE", []string{"stations", "*"}, permManage, deleteStation},
+	{"GET", []string{"stations", "*", "playlist"}, permListen, getPlaylist},
+	{"POST", []string{"feedback"}, permRate, addFeedback},
+	{"DELETE", []string{"feedback", "*"}, permRate, deleteFeedback},
+	{"GET", []string{"tracks", "*", "explain"}, permRead, explainTrack},
+	{"POST", []string{"tracks", "*", "sleep"}, permRate, sleepTrack},
+	{"GET", []string{"search"}, permRead, search},
+	{"GET", []string{"bookmarks"}, permRead, getBookmarks},
+	{"POST", []string{"bookmarks"}, permBookmark, addBookmark},
+}
+
+// match reports whether the path segments fit the pattern and returns the
+// segments matched by wildcards.
+func (rt *route) match(segs []string) ([]string, bool) {
+	if len(segs) != len(rt.pattern) {
+		return nil, false
+	}
+	var params []string
+	for i, p := range rt.pattern {
+		switch p {
+		case "*":
+			params = append(params, segs[i])
+		case segs[i]:
+		default:
+			return nil, false
+		}
+	}
+	return params, true
+}
+
+type server struct {
+	// mu serializes use of the client, which is not safe for concurrent use.
+	mu     sync.Mutex
+	client *gopiano.Client
+	keys   []apiKey
+}
+
+func newServer(client *gopiano.Client, keys []apiKey) *server {
+	return &server{client: client, keys: keys}
+}
+
+// call runs fn with exclusive use of the client, logging in again if the
+// session expired.
+func (s *server) call(fn func(c *gopiano.Client) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.client.WithRelogin(func() error {
+		return fn(s.client)
+	})
+}
+
+func (s *server) authenticate(r *http.Request) *apiKey {
+	key := r.Header.Get("X-API-Key")
+	if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+		key = strings.TrimPrefix(auth, "Bearer ")
+	}
+	if key == "" {
+		return nil
+	}
+
+	var found *apiKey
+	for i := range s.keys {
+		// Compare every key in constant time so timing reveals nothing.
+		if subtle.ConstantTimeCompare([]byte(s.keys[i].Key), []byte(key)) == 1 {
+			found = &s.keys[i]
+		}
+	}
+	return found
+}
+
+func (s *server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	v, err := s.serve(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	if v == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("%s %s: %v", r.Method, r.URL.Path, err)
+	}
+}
+
+func (s *server) serve(r *http.Request) (interface{}, error) {
+	key := s.authenticate(r)
+	if key == nil {
+		return nil, errUnauthorized
+	}
+
+	segs := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	found := false
+	for i := range routes {
+		rt := &routes[i]
+		params, ok := rt.match(segs)
+		if !ok {
+			continue
+		}
+		found = true
+		if rt.method != r.Method {
+			continue
+		}
+
+		if !key.can(rt.perm) {
+			return nil, errForbidden
+		}
+		return rt.handle(s, r, params)
+	}
+
+	if found {
+		return nil, errMethod
+	}
+	return nil, errNotFound
+}
+
+func writeError(w http.ResponseWriter, r *http.Request, err error) {
+	body := struct {
+		Error string `json:"error"`
+		Code  int    `json:"code,omitempty"`
+	}{Error: err.Error()}
+
+	status := http.StatusInternalServerError
+	switch e := err.(type) {
+	case *httpError:
+		status = e.status
+	case response.ErrorResponse:
+		body.Code = e.Code
+		status = http.StatusBadGateway
+		switch e.Code {
+		case 1006: // STATION_DOES_NOT_EXIST
+			status = http.StatusNotFound
+		case 9, 10: // PARAMETER_MISSING, PARAMETER_VALUE_INVALID
+			status = http.StatusBadRequest
+		}
+	default:
+		log.Printf("%s %s: %v", r.Method, r.URL.Path, err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+// decode reads the JSON request body into v.
+func decode(r *http.Request, v interface{}) error {
+	dec := json.NewDecoder(http.MaxBytesReader(nil, r.Body, maxBody))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return badRequest("invalid request body: %v", err)
+	}
+	return nil
+}
+
+func getStations(s *server, r *http.Request, _ []string) (interface{}, error) {
+	var resp *response.UserGetStationList
+	err := s.call(func(c *gopiano.Client) (err error) {
+		resp, err = c.UserGetStationList(r.URL.Query().Get("art") != "")
+		return err
+	})
+	return resp, err
+}
+
+func createStation(s *server, r *http.Request, _ []string) (interface{}, error) {
+	var body struct {
+		MusicToken string `json:"musicToken"`
+		TrackToken string `json:"trackToken"`
+		MusicType  string `json:"musicType"`
+	}
+	if err := decode(r, &body); err != nil {
+		return nil, err
+	}
+
+	var resp *response.StationCreateStation
+	var err error
+	switch {
+	case body.MusicToken != "":
+		err = s.call(func(c *gopiano.Client) (err error) {
+			resp, err = c.StationCreateStationMusic(body.MusicToken)
+			return err
+		})
+	case body.TrackToken != "" && (body.MusicType == "song" || body.MusicType == "artist"):
+		err = s.call(func(c *gopiano.Client) (err error) {
+			resp, err = c.StationCreateStationTrack(body.TrackToken, body.MusicType)
+			return err
+		})
+	default:
+		return nil, badRequest(`need musicToken, or trackToken and a musicType of "song" or "artist"`)
+	}
+	return resp, err
+}
+
+func getStation(s *server, r *http.Request, params []string) (interface{}, error) {
+	var resp *response.StationGetStation
+	err := s.call(func(c *gopiano.Client) (err error) {
+		resp, err = c.StationGetStation(params[0], true)
+		return err
+	})
+	return resp, err
+}
+
+func renameStation(s *server, r *http.Request, params []string) (interface{}, error) {
+	var body struct {
+		Name string `json:"name"`
+	}
+	if err := decode(r, &body); err != nil {
+		return nil, err
+	}
+	if body.Name == "" {
+		return nil, badRequest("need name")
+	}
+
+	var resp *response.StationRenameStation
+	err := s.call(func(c *gopiano.Client) (err error) {
+		resp, err = c.StationRenameStation(params[0], body.Name)
+		return err
+	})
+	return resp, err
+}
+
+func deleteStation(s *server, r *http.Request, params []string) (interface{}, error) {
+	return nil, s.call(func(c *gopiano.Client) error {
+		return c.StationDeleteStation(params[0])
+	})
+}
+
+func getPlaylist(s *server, r *http.Request, params []string) (interface{}, error) {
+	var resp *response.StationGetPlaylist
+	err := s.call(func(c *gopiano.Client) (err error) {
+		resp, err = c.StationGetPlaylist(params[0])
+		return err
+	})
+	return resp, err
+}
+
+func addFeedback(s *server, r *http.Request, _ []string) (interface{}, error) {
+	var body struct {
+		StationToken string `json:"stationToken"`
+		TrackToken   string `json:"trackToken"`
+		IsPositive   *bool  `json:"isPositive"`
+	}
+	if err := decode(r, &body); err != nil {
+		return nil, err
+	}
+	if body.StationToken == "" || body.TrackToken == "" || body.IsPositive == nil {
+		return nil, badRequest("need stationToken, trackToken and isPositive")
+	}
+
+	var resp *response.StationAddFeedback
+	err := s.call(func(c *gopiano.Client) (err error) {
+		resp, err = c.StationAddFeedback(body.StationToken, body.TrackToken, *body.IsPositive)
+		return err
+	})
+	return resp, err
+}
+
+func deleteFeedback(s *server, r *http.Request, params []string) (interface{}, error) {
+	return nil, s.call(func(c *gopiano.Client) error {
+		return c.StationDeleteFeedback(params[0])
+	})
+}
+
+func explainTrack(s *server, r *http.Request, params []string) (interface{}, error) {
+	var resp *response.ExplainTrack
+	err := s.call(func(c *gopiano.Client) (err error) {
+		resp, err = c.ExplainTrack(params[0])
+		return err
+	})
+	return resp, err
+}
+
+func sleepTrack(s *server, r *http.Request, params []string) (interface{}, error) {
+	return nil, s.call(func(c *gopiano.Client) error {
+		return c.UserSleepSong(params[0])
+	})
+}
+
+func search(s *server, r *http.Request, _ []string) (interface{}, error) {
+	q := r.URL.Query().Get("q")
+	if q == "" {
+		return nil, badRequest("need q")
+	}
+
+	var resp *response.MusicSearch
+	err := s.call(func(c *gopiano.Client) (err error) {
+		resp, err = c.MusicSearch(q)
+		return err
+	})
+	return resp, err
+}
+
+func getBookmarks(s *server, r *http.Request, _ []string) (interface{}, error) {
+	var resp *response.UserGetBookmarks
+	err := s.call(func(c *gopiano.Client) (err error) {
+		resp, err = c.UserGetBookmarks()
+		return err
+	})
+	return resp, err
+}
+
+func addBookmark(s *server, r *http.Request, _ []string) (interface{}, error) {
+	var body struct {
+		TrackToken string `json:"trackToken"`
+		Type       string `json:"type"`
+	}
+	if err := decode(r, &body); err != nil {
+		return nil, err
+	}
+	if body.TrackToken == "" {
+		return nil, badRequest("need trackToken")
+	}
+
+	var resp interface{}
+	err := s.call(func(c *gopiano.Client) (err error) {
+		switch body.Type {
+		case "song", "":
+			resp, err = c.BookmarkAddSongBookmark(body.TrackToken)
+		case "artist":
+			resp, err = c.BookmarkAddArtistBookmark(body.TrackToken)
+		default:
+			err = badRequest(`type must be "song" or "artist"`)
+		}
+		return err
+	})
+	return resp, err
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestServerAuth(t *testing.T) {
+	s := newServer(nil, []apiKey{
+		{Name: "dashboard", Key: "read-key", Permissions: []string{permRead}},
+		{Name: "admin", Key: "admin-key", Permissions: []string{permAll}},
+	})
+
+	data := []struct {
+		Method, Path, Key, Body string
+		Status                  int
+	}{
+		{"GET", "/stations", "", "", http.StatusUnauthorized},
+		{"GET", "/stations", "wrong-key", "", http.StatusUnauthorized},
+		{"DELETE", "/stations/123", "read-key", "", http.StatusForbidden},
+		{"GET", "/stations/123/playlist", "read-key", "", http.StatusForbidden},
+		{"POST", "/feedback", "read-key", `{}`, http.StatusForbidden},
+		{"GET", "/nothing", "read-key", "", http.StatusNotFound},
+		{"PUT", "/stations", "read-key", "", http.StatusMethodNotAllowed},
+		{"POST", "/feedback", "admin-key", `{"trackToken": "t"}`, http.StatusBadRequest},
+		{"POST", "/stations", "admin-key", `{"trackToken": "t", "musicType": "album"}`, http.StatusBadRequest},
+		{"PATCH", "/stations/123", "admin-key", `{"name": 1}`, http.StatusBadRequest},
+		{"GET", "/search", "read-key", "", http.StatusBadRequest},
+	}
+
+	for _, d := range data {
+		r := httptest.NewRequest(d.Method, d.Path, strings.NewReader(d.Body))
+		if d.Key != "" {
+			r.Header.Set("Authorization", "Bearer "+d.Key)
+		}
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, r)
+
+		if w.Code != d.Status {
+			t.Errorf("%s %s with %q: expected status %d, got %d: %s", d.Method, d.Path, d.Key, d.Status, w.Code, w.Body)
+		}
+	}
+}
+
+func TestAPIKeyCheck(t *testing.T) {
+	if err := (&apiKey{Name: "a", Key: "k", Permissions: []string{permRead, permRate}}).check(); err != nil {
+		t.Error(err)
+	}
+	if err := (&apiKey{Name: "a", Key: "k", Permissions: []string{"write"}}).check(); err == nil {
+		t.Error("expected an error for an unknown permission")
+	}
+	if err := (&apiKey{Name: "a"}).check(); err == nil {
+		t.Error("expected an error for an empty key")
+	}
+}
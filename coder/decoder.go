@@ -0,0 +1,104 @@
+package coder
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"io"
+
+	"golang.org/x/crypto/blowfish"
+)
+
+var (
+	// ErrLength is returned when the input ends inside a block.
+	ErrLength = errors.New("coder: input is not a whole number of blocks")
+	// ErrPadding is returned when the NUL padding of the last block is
+	// followed by other bytes.
+	ErrPadding = errors.New("coder: non-zero byte in padding")
+)
+
+// NewDecoder returns a reader that reverses the encoding of New: it hex
+// decodes what it reads from r and blowfish decrypts it (ECB mode),
+// removing the NUL padding of the last block.
+//
+// Malformed hex is reported as a hex.InvalidByteError, input that ends
+// inside a block as ErrLength and a damaged last block as ErrPadding.
+func NewDecoder(cipher *blowfish.Cipher, r io.Reader) io.Reader {
+	return &decoder{r: r, blow: cipher}
+}
+
+type decoder struct {
+	r    io.Reader
+	blow *blowfish.Cipher
+	in   []byte       // hex not decoded yet
+	last []byte       // the latest block, held back until it is known not to be the last
+	out  bytes.Buffer // decoded data ready to be read
+	err  error
+}
+
+func (dec *decoder) Read(p []byte) (n int, err error) {
+	for dec.out.Len() == 0 && dec.err == nil {
+		dec.fill()
+	}
+	if dec.out.Len() > 0 {
+		return dec.out.Read(p)
+	}
+	return 0, dec.err
+}
+
+func (dec *decoder) fill() {
+	var buf [512]byte
+	n, err := dec.r.Read(buf[:])
+	dec.in = append(dec.in, buf[:n]...)
+
+	in := dec.in
+	for ; 2*blowfish.BlockSize <= len(in); in = in[2*blowfish.BlockSize:] {
+		block := make([]byte, blowfish.BlockSize)
+		if _, err := hex.Decode(block, in[:2*blowfish.BlockSize]); err != nil {
+			dec.err = err
+			return
+		}
+		dec.blow.Decrypt(block, block)
+
+		if dec.last != nil {
+			dec.out.Write(dec.last)
+		}
+		dec.last = block
+	}
+	dec.in = append(dec.in[:0], in...)
+
+	switch {
+	case err == io.EOF:
+		dec.finish()
+	case err != nil:
+		dec.err = err
+	}
+}
+
+// finish checks and writes out the last block at the end of the input.
+func (dec *decoder) finish() {
+	if len(dec.in) != 0 {
+		if _, err := hex.Decode(make([]byte, len(dec.in)/2), dec.in[:len(dec.in)&^1]); err != nil {
+			dec.err = err
+		} else {
+			dec.err = ErrLength
+		}
+		return
+	}
+
+	if dec.last != nil {
+		data := dec.last
+		if i := bytes.IndexByte(data, 0); i >= 0 {
+			for _, b := range data[i:] {
+				if b != 0 {
+					dec.err = ErrPadding
+					return
+				}
+			}
+			data = data[:i]
+		}
+		dec.out.Write(data)
+		dec.last = nil
+	}
+	dec.err = io.EOF
+}
@@ -0,0 +1,68 @@
+package coder
+
+import (
+	"bytes"
+	"encoding/hex"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/blowfish"
+)
+
+// oneByteReader reads at most one byte at a time.
+type oneByteReader struct {
+	r *strings.Reader
+}
+
+func (o oneByteReader) Read(p []byte) (int, error) {
+	if len(p) > 1 {
+		p = p[:1]
+	}
+	return o.r.Read(p)
+}
+
+func TestDecoder(t *testing.T) {
+	c, err := blowfish.NewCipher([]byte("R=U!LH$O2B#"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 40; i++ {
+		data := bytes.Repeat([]byte("x"), i)
+
+		enc := New(c)
+		enc.Write(data)
+		encoded, _ := ioutil.ReadAll(enc)
+
+		got, err := ioutil.ReadAll(NewDecoder(c, oneByteReader{strings.NewReader(string(encoded))}))
+		if err != nil {
+			t.Errorf("%d bytes: %v", i, err)
+		} else if !bytes.Equal(got, data) {
+			t.Errorf("\nexpected:\n\t%q\ngot:\n\t%q", data, got)
+		}
+	}
+
+	block := func(s string) string {
+		b := []byte(s)
+		c.Encrypt(b, b)
+		return hex.EncodeToString(b)
+	}
+
+	errs := []struct {
+		Input string
+		Err   string
+	}{
+		{block("12345678") + "abc", ErrLength.Error()},
+		{block("12345678")[:15], ErrLength.Error()},
+		{block("12345678") + "zz", "encoding/hex: invalid byte: U+007A 'z'"},
+		{"zz" + block("12345678")[2:], "encoding/hex: invalid byte: U+007A 'z'"},
+		{block("12345678") + block("ab\x00\x00c\x00\x00\x00"), ErrPadding.Error()},
+	}
+	for _, e := range errs {
+		_, err := ioutil.ReadAll(NewDecoder(c, strings.NewReader(e.Input)))
+		if err == nil || err.Error() != e.Err {
+			t.Errorf("%q:\nexpected:\n\t%q\ngot:\n\t%v", e.Input, e.Err, err)
+		}
+	}
+}
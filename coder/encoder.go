@@ -12,6 +12,8 @@ import (
 // blowfish encrypting (ECB mode) followed by hex encoding.
 //
 // EOF reads will encrypt the last block padded with 0x00.
+//
+// NewEncoder does the same without holding all output in memory.
 func New(cipher *blowfish.Cipher) io.ReadWriter {
 	return &encoder{blow: cipher}
 }
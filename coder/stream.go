@@ -0,0 +1,106 @@
+package coder
+
+import (
+	"encoding/hex"
+	"io"
+	"sync"
+
+	"golang.org/x/crypto/blowfish"
+)
+
+// batchSize is how much plain text a stream encoder collects before
+// encrypting it in one go. It is a multiple of the block size.
+const batchSize = 2048
+
+type buffers struct {
+	plain [batchSize]byte
+	hex   [2 * batchSize]byte
+}
+
+var bufferPool = sync.Pool{
+	New: func() interface{} { return new(buffers) },
+}
+
+// NewEncoder returns a writer that blowfish encrypts (ECB mode) and hex
+// encodes everything written to it and passes the result on to w.
+// Data is encrypted in batches as it arrives, so the output can be
+// streamed, e.g. through an io.Pipe into an HTTP request.
+//
+// Close must be called at the end to write the last block, padded with
+// 0x00. It does not close w.
+func NewEncoder(cipher *blowfish.Cipher, w io.Writer) io.WriteCloser {
+	return &streamEncoder{
+		w:    w,
+		blow: cipher,
+		buf:  bufferPool.Get().(*buffers),
+	}
+}
+
+type streamEncoder struct {
+	w    io.Writer
+	blow *blowfish.Cipher
+	buf  *buffers // nil once closed
+	n    int      // plain text bytes in buf
+	err  error
+}
+
+func (enc *streamEncoder) Write(p []byte) (n int, err error) {
+	if enc.err != nil {
+		return 0, enc.err
+	}
+	if enc.buf == nil {
+		return 0, io.ErrClosedPipe
+	}
+
+	for len(p) > 0 {
+		c := copy(enc.buf.plain[enc.n:], p)
+		enc.n += c
+		n += c
+		p = p[c:]
+
+		if enc.n == batchSize {
+			if err := enc.flush(batchSize); err != nil {
+				return n, err
+			}
+		}
+	}
+	return n, nil
+}
+
+// flush encrypts and writes the first size bytes of the buffer, which
+// must be a whole number of blocks.
+func (enc *streamEncoder) flush(size int) error {
+	plain := enc.buf.plain[:size]
+	for i := 0; i < size; i += blowfish.BlockSize {
+		enc.blow.Encrypt(plain[i:i+blowfish.BlockSize], plain[i:i+blowfish.BlockSize])
+	}
+	hex.Encode(enc.buf.hex[:], plain)
+
+	enc.n = copy(enc.buf.plain[:], enc.buf.plain[size:enc.n])
+	if _, err := enc.w.Write(enc.buf.hex[:2*size]); err != nil {
+		enc.err = err
+		return err
+	}
+	return nil
+}
+
+// Close pads and writes the last block.
+func (enc *streamEncoder) Close() error {
+	if enc.buf == nil {
+		return enc.err
+	}
+	defer func() {
+		bufferPool.Put(enc.buf)
+		enc.buf = nil
+	}()
+	if enc.err != nil {
+		return enc.err
+	}
+
+	size := (enc.n + blowfish.BlockSize - 1) &^ (blowfish.BlockSize - 1)
+	for i := enc.n; i < size; i++ {
+		enc.buf.plain[i] = 0
+	}
+	enc.n = size
+	return enc.flush(size)
+}
@@ -0,0 +1,72 @@
+package coder
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+
+	"golang.org/x/crypto/blowfish"
+)
+
+func TestNewEncoder(t *testing.T) {
+	c, err := blowfish.NewCipher([]byte("6#26FRL$ZWD"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, size := range []int{0, 1, 7, 8, 9, batchSize - 1, batchSize, batchSize + 1, 3*batchSize + 5} {
+		data := bytes.Repeat([]byte("abc"), size)[:size]
+
+		old := New(c)
+		old.Write(data)
+		expected, _ := ioutil.ReadAll(old)
+
+		// Write in uneven pieces to cross batch boundaries.
+		var buf bytes.Buffer
+		enc := NewEncoder(c, &buf)
+		for p := data; len(p) > 0; {
+			n := 100
+			if n > len(p) {
+				n = len(p)
+			}
+			enc.Write(p[:n])
+			p = p[n:]
+		}
+		if err := enc.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		if !bytes.Equal(buf.Bytes(), expected) {
+			t.Errorf("%d bytes: output differs from New", size)
+		}
+
+		decoded, err := ioutil.ReadAll(NewDecoder(c, &buf))
+		if err != nil || !bytes.Equal(decoded, data) {
+			t.Errorf("%d bytes: round trip failed: %v", size, err)
+		}
+	}
+}
+
+func benchmarkEncoder(b *testing.B, size int, stream bool) {
+	c, _ := blowfish.NewCipher([]byte("6#26FRL$ZWD"))
+	data := bytes.Repeat([]byte("x"), size)
+
+	b.ReportAllocs()
+	b.SetBytes(int64(size))
+	for i := 0; i < b.N; i++ {
+		if stream {
+			enc := NewEncoder(c, ioutil.Discard)
+			enc.Write(data)
+			enc.Close()
+		} else {
+			enc := New(c)
+			enc.Write(data)
+			ioutil.ReadAll(enc)
+		}
+	}
+}
+
+func BenchmarkNewSmall(b *testing.B)     { benchmarkEncoder(b, 200, false) }
+func BenchmarkNewLarge(b *testing.B)     { benchmarkEncoder(b, 64<<10, false) }
+func BenchmarkEncoderSmall(b *testing.B) { benchmarkEncoder(b, 200, true) }
+func BenchmarkEncoderLarge(b *testing.B) { benchmarkEncoder(b, 64<<10, true) }
@@ -65,3 +65,19 @@ var AirClient = ClientDescription{
 	DecryptKey:  "U#IO$RZPAB%VX2",
 	Version:     "5",
 }
+
+var clientsByName = map[string]ClientDescription{
+	"android": AndroidClient,
+	"ios":     IOSClient,
+	"palm":    PalmClient,
+	"winmo":   WinMoClient,
+	"vista":   VistaClient,
+	"air":     AirClient,
+}
+
+// ClientByName returns the client description called name, one of
+// "android", "ios", "palm", "winmo", "vista" or "air".
+func ClientByName(name string) (ClientDescription, bool) {
+	desc, ok := clientsByName[name]
+	return desc, ok
+}
@@ -0,0 +1,38 @@
+package gopiano
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"denniskupec.com/gopiano/response"
+)
+
+func TestOnSchemaDrift(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"stat": "ok", "result": {"checksum": "abc", "shiny": 1}}`)
+	}))
+	defer srv.Close()
+	target, _ := url.Parse(srv.URL)
+
+	c, _ := NewClient(AndroidClient)
+	c.HTTPClient = &http.Client{Transport: redirect{target}}
+
+	var drift []response.SchemaDrift
+	c.OnSchemaDrift = func(d response.SchemaDrift) {
+		drift = append(drift, d)
+	}
+
+	resp, err := c.UserGetStationListChecksum()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Checksum != "abc" || string(resp.Extra["shiny"]) != "1" {
+		t.Errorf("unexpected response %+v", resp)
+	}
+	if len(drift) != 1 || drift[0].Method != "user.getStationListChecksum" || len(drift[0].Unknown) != 1 || drift[0].Unknown[0] != "shiny" {
+		t.Errorf("unexpected drift %+v", drift)
+	}
+}
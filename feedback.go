@@ -0,0 +1,212 @@
+package gopiano
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"denniskupec.com/gopiano/response"
+)
+
+// DefaultFeedbackWorkers is the number of stations a FeedbackManager
+// fetches at the same time unless told otherwise.
+const DefaultFeedbackWorkers = 4
+
+// Feedback is a thumbs up or down given on one of the user's stations.
+type Feedback struct {
+	response.FeedbackResponse
+	StationToken string
+	StationName  string
+}
+
+// FeedbackList is the feedback of several stations.
+type FeedbackList []Feedback
+
+// FeedbackFilter selects feedback. Zero fields match everything.
+type FeedbackFilter struct {
+	Artist  string    // artist name, ignoring case
+	Station string    // station token, ID or name, ignoring case
+	After   time.Time // given at or after this time
+	Before  time.Time // given before this time
+}
+
+// FeedbackManager works with the feedback of all stations of the user's
+// account at once.
+//
+// Feedback cannot be moved to another station: Pandora only takes feedback
+// for tracks of a playlist, which a FeedbackResponse does not refer to, so
+// it could only be deleted. See ErrFeedbackNotRestorable.
+type FeedbackManager struct {
+	c *Client
+
+	// Workers limits the number of stations fetched at the same time.
+	// If zero, DefaultFeedbackWorkers is used.
+	Workers int
+}
+
+// FeedbackManager returns a FeedbackManager for the user's account.
+func (c *Client) FeedbackManager() *FeedbackManager {
+	return &FeedbackManager{c: c}
+}
+
+// List returns the feedback of every station except the QuickMix, in the
+// order of the station list, thumbs up before thumbs down. Up to Workers
+// stations are fetched at the same time.
+func (m *FeedbackManager) List() (FeedbackList, error) {
+	list, err := m.c.UserGetStationList(false)
+	if err != nil {
+		return nil, err
+	}
+
+	var stations response.StationList
+	for _, s := range list.Stations {
+		if !s.IsQuickMix {
+			stations = append(stations, s)
+		}
+	}
+
+	workers := m.Workers
+	if workers <= 0 {
+		workers = DefaultFeedbackWorkers
+	}
+
+	feedback := make([]FeedbackList, len(stations))
+	errs := make([]error, len(stations))
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				feedback[i], errs[i] = m.station(stations[i].StationToken)
+			}
+		}()
+	}
+	for i := range stations {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+
+	var all FeedbackList
+	for i := range stations {
+		if errs[i] != nil {
+			return nil, errs[i]
+		}
+		all = append(all, feedback[i]...)
+	}
+	return all, nil
+}
+
+// station fetches the feedback of a single station.
+func (m *FeedbackManager) station(token string) (FeedbackList, error) {
+	resp, err := m.c.StationGetStation(token, true)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &resp.Result
+	var l FeedbackList
+	for _, f := range s.Feedback.ThumbsUp {
+		l = append(l, Feedback{FeedbackResponse: f, StationToken: s.StationToken, StationName: s.StationName})
+	}
+	for _, f := range s.Feedback.ThumbsDown {
+		l = append(l, Feedback{FeedbackResponse: f, StationToken: s.StationToken, StationName: s.StationName})
+	}
+	return l, nil
+}
+
+// Delete removes the given feedback. Feedback that cannot be removed is
+// listed in the result instead of stopping the others.
+func (m *FeedbackManager) Delete(l FeedbackList) []ImportProblem {
+	var problems []ImportProblem
+	for _, f := range l {
+		if err := m.c.StationDeleteFeedback(f.FeedbackID); err != nil {
+			problems = append(problems, ImportProblem{Station: f.StationName, Music: feedbackMusic(f.FeedbackResponse), Err: err})
+		}
+	}
+	return problems
+}
+
+// Filter returns the feedback matching f.
+func (l FeedbackList) Filter(f FeedbackFilter) FeedbackList {
+	var out FeedbackList
+	for _, fb := range l {
+		if f.match(fb) {
+			out = append(out, fb)
+		}
+	}
+	return out
+}
+
+func (f FeedbackFilter) match(fb Feedback) bool {
+	same := func(a, b string) bool {
+		return strings.EqualFold(strings.TrimSpace(a), strings.TrimSpace(b))
+	}
+
+	switch {
+	case f.Artist != "" && !same(f.Artist, fb.ArtistName):
+		return false
+	case f.Station != "" && f.Station != fb.StationToken && !same(f.Station, fb.StationName):
+		return false
+	case !f.After.IsZero() && fb.DateCreated.Before(f.After):
+		return false
+	case !f.Before.IsZero() && !fb.DateCreated.Before(f.Before):
+		return false
+	}
+	return true
+}
+
+// feedbackRecord is the exported form of Feedback.
+type feedbackRecord struct {
+	Station    string    `json:"station"`
+	ArtistName string    `json:"artistName"`
+	SongName   string    `json:"songName"`
+	IsPositive bool      `json:"isPositive"`
+	Date       time.Time `json:"date"`
+	FeedbackID string    `json:"feedbackId"`
+}
+
+func (fb Feedback) record() feedbackRecord {
+	return feedbackRecord{
+		Station:    fb.StationName,
+		ArtistName: fb.ArtistName,
+		SongName:   fb.SongName,
+		IsPositive: fb.IsPositive,
+		Date:       fb.DateCreated.UTC(),
+		FeedbackID: fb.FeedbackID,
+	}
+}
+
+// WriteJSON writes the feedback as a JSON array.
+func (l FeedbackList) WriteJSON(w io.Writer) error {
+	records := make([]feedbackRecord, 0, len(l))
+	for _, fb := range l {
+		records = append(records, fb.record())
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(records)
+}
+
+// WriteCSV writes the feedback as CSV with a header line. The rating is
+// "up" or "down", and the date is in RFC 3339 format.
+func (l FeedbackList) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"station", "artist", "song", "rating", "date", "feedback_id"})
+	for _, fb := range l {
+		r := fb.record()
+		rating := "down"
+		if r.IsPositive {
+			rating = "up"
+		}
+		cw.Write([]string{r.Station, r.ArtistName, r.SongName, rating, r.Date.Format(time.RFC3339), r.FeedbackID})
+	}
+	cw.Flush()
+	return cw.Error()
+}
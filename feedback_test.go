@@ -0,0 +1,217 @@
+package gopiano
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"denniskupec.com/gopiano/request"
+	"denniskupec.com/gopiano/response"
+)
+
+func feedbackAt(artist, song string, positive bool, day int) response.FeedbackResponse {
+	return response.FeedbackResponse{
+		ArtistName:  artist,
+		SongName:    song,
+		IsPositive:  positive,
+		FeedbackID:  artist + "/" + song,
+		DateCreated: response.Date{Time: time.Date(2020, 1, day, 12, 0, 0, 0, time.UTC)},
+	}
+}
+
+func TestFeedbackList(t *testing.T) {
+	stations := map[string]response.Station{
+		"t1": {StationToken: "t1", StationName: "Jazz", Feedback: response.StationFeedback{
+			ThumbsUp:   []response.FeedbackResponse{feedbackAt("Miles Davis", "So What", true, 1)},
+			ThumbsDown: []response.FeedbackResponse{feedbackAt("Kenny G", "Songbird", false, 2)},
+		}},
+		"t2": {StationToken: "t2", StationName: "Rock", Feedback: response.StationFeedback{
+			ThumbsUp: []response.FeedbackResponse{feedbackAt("Queen", "Bohemian Rhapsody", true, 3)},
+		}},
+	}
+
+	c, _ := NewClient(AndroidClient)
+	c.Interceptors = []Interceptor{func(req request.Type, data interface{}, next Invoker) error {
+		switch r := req.(type) {
+		case request.GetStationList:
+			data.(*response.UserGetStationList).Stations = response.StationList{
+				{StationToken: "qm", IsQuickMix: true},
+				stations["t1"],
+				stations["t2"],
+			}
+		case request.GetStation:
+			if r.StationToken == "qm" {
+				t.Error("QuickMix fetched")
+			}
+			data.(*response.StationGetStation).Result = stations[r.StationToken]
+		}
+		return nil
+	}}
+
+	l, err := c.FeedbackManager().List()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, f := range l {
+		got = append(got, f.StationName+": "+f.FeedbackID)
+	}
+	expected := []string{"Jazz: Miles Davis/So What", "Jazz: Kenny G/Songbird", "Rock: Queen/Bohemian Rhapsody"}
+	if len(got) != len(expected) {
+		t.Fatalf("\nexpected:\n\t%q\ngot:\n\t%q", expected, got)
+	}
+	for i := range got {
+		if got[i] != expected[i] {
+			t.Errorf("\nexpected:\n\t%q\ngot:\n\t%q", expected[i], got[i])
+		}
+	}
+
+	filters := []struct {
+		Filter FeedbackFilter
+		Count  int
+	}{
+		{FeedbackFilter{}, 3},
+		{FeedbackFilter{Artist: "kenny g"}, 1},
+		{FeedbackFilter{Station: "jazz"}, 2},
+		{FeedbackFilter{Station: "t2"}, 1},
+		{FeedbackFilter{After: time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)}, 2},
+		{FeedbackFilter{Before: time.Date(2020, 1, 2, 12, 0, 0, 0, time.UTC)}, 1},
+		{FeedbackFilter{Artist: "Queen", Station: "Jazz"}, 0},
+	}
+	for _, f := range filters {
+		if n := len(l.Filter(f.Filter)); n != f.Count {
+			t.Errorf("%+v: expected %d, got %d", f.Filter, f.Count, n)
+		}
+	}
+}
+
+func TestFeedbackListWorkers(t *testing.T) {
+	pandoraTime := time.Now().Unix()
+	logins := 0
+
+	var mu sync.Mutex
+	running, most := 0, 0
+	c, _ := NewClient(AndroidClient)
+	c.ResyncInterval = time.Nanosecond
+	c.Interceptors = []Interceptor{func(req request.Type, data interface{}, next Invoker) error {
+		switch r := req.(type) {
+		case request.GetStationList:
+			list := &data.(*response.UserGetStationList).Stations
+			for i := 0; i < 12; i++ {
+				*list = append(*list, response.Station{StationToken: strconv.Itoa(i)})
+			}
+		case request.GetStation:
+			mu.Lock()
+			running++
+			if running > most {
+				most = running
+			}
+			mu.Unlock()
+			time.Sleep(5 * time.Millisecond)
+			mu.Lock()
+			running--
+			mu.Unlock()
+
+			data.(*response.StationGetStation).Result = response.Station{
+				StationToken: r.StationToken,
+				Feedback:     response.StationFeedback{ThumbsUp: []response.FeedbackResponse{{FeedbackID: r.StationToken}}},
+			}
+		default:
+			return next(req, data)
+		}
+		return nil
+	}, fakeSession(&pandoraTime, &logins)}
+	if _, err := c.AuthPartnerLogin(); err != nil {
+		t.Fatal(err)
+	}
+
+	l, err := (&FeedbackManager{c: c, Workers: 3}).List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, f := range l {
+		if f.FeedbackID != strconv.Itoa(i) {
+			t.Errorf("%d: expected feedback of station %d, got %q", i, i, f.FeedbackID)
+		}
+	}
+	if len(l) != 12 {
+		t.Errorf("expected 12 feedback, got %d", len(l))
+	}
+	if most < 2 || most > 3 {
+		t.Errorf("expected 2 or 3 stations fetched at the same time, got %d", most)
+	}
+}
+
+func TestFeedbackDelete(t *testing.T) {
+	l := FeedbackList{
+		{FeedbackResponse: feedbackAt("Miles Davis", "So What", true, 1), StationName: "Jazz"},
+		{FeedbackResponse: feedbackAt("Kenny G", "Songbird", false, 2), StationName: "Jazz"},
+		{FeedbackResponse: feedbackAt("Queen", "Bohemian Rhapsody", true, 3), StationName: "Rock"},
+	}
+
+	var deleted []string
+	c, _ := NewClient(AndroidClient)
+	c.Interceptors = []Interceptor{func(req request.Type, data interface{}, next Invoker) error {
+		r, ok := req.(request.DeleteFeedback)
+		if !ok {
+			t.Errorf("unexpected request %T", req)
+			return nil
+		}
+		if r.FeedbackID == "Kenny G/Songbird" {
+			return errors.New("failed")
+		}
+		deleted = append(deleted, r.FeedbackID)
+		return nil
+	}}
+
+	problems := c.FeedbackManager().Delete(l)
+	expected := []string{"Miles Davis/So What", "Queen/Bohemian Rhapsody"}
+	if !reflect.DeepEqual(deleted, expected) {
+		t.Errorf("\nexpected:\n\t%q\ngot:\n\t%q", expected, deleted)
+	}
+	if len(problems) != 1 || problems[0].Station != "Jazz" || problems[0].Music.SongName != "Songbird" {
+		t.Errorf("expected a problem with Songbird on Jazz, got %+v", problems)
+	}
+}
+
+func TestFeedbackExport(t *testing.T) {
+	l := FeedbackList{
+		{FeedbackResponse: feedbackAt("Miles Davis", "So What", true, 1), StationName: "Jazz"},
+		{FeedbackResponse: feedbackAt("Kenny G", "Songbird, Live", false, 2), StationName: "Jazz"},
+	}
+
+	var buf bytes.Buffer
+	if err := l.WriteCSV(&buf); err != nil {
+		t.Fatal(err)
+	}
+	expected := "station,artist,song,rating,date,feedback_id\n" +
+		"Jazz,Miles Davis,So What,up,2020-01-01T12:00:00Z,Miles Davis/So What\n" +
+		"Jazz,Kenny G,\"Songbird, Live\",down,2020-01-02T12:00:00Z,\"Kenny G/Songbird, Live\"\n"
+	if got := buf.String(); got != expected {
+		t.Errorf("\nexpected:\n\t%q\ngot:\n\t%q", expected, got)
+	}
+
+	buf.Reset()
+	if err := l[:1].WriteJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	expected = `[
+  {
+    "station": "Jazz",
+    "artistName": "Miles Davis",
+    "songName": "So What",
+    "isPositive": true,
+    "date": "2020-01-01T12:00:00Z",
+    "feedbackId": "Miles Davis/So What"
+  }
+]
+`
+	if got := buf.String(); got != expected {
+		t.Errorf("\nexpected:\n\t%q\ngot:\n\t%q", expected, got)
+	}
+}
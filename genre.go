@@ -0,0 +1,218 @@
+package gopiano
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"denniskupec.com/gopiano/response"
+)
+
+// GenreCatalog holds Pandora's predefined genre stations, so they can be
+// looked up by name.
+type GenreCatalog struct {
+	Checksum   string
+	Categories []response.GenreCategory
+}
+
+// GenreMatch is a genre station found in a GenreCatalog.
+type GenreMatch struct {
+	Category string
+	Station  response.GenreStation
+}
+
+// GenreCatalog returns the genre stations. The catalog is cached by the
+// client and only fetched again when its checksum changes.
+func (c *Client) GenreCatalog() (*GenreCatalog, error) {
+	c.genreMu.Lock()
+	defer c.genreMu.Unlock()
+
+	sum, err := c.StationGetGenreStationsChecksum()
+	if err != nil {
+		return nil, err
+	}
+	if c.genres != nil && c.genres.Checksum == sum.Checksum {
+		return c.genres, nil
+	}
+
+	resp, err := c.StationGetGenreStations()
+	if err != nil {
+		return nil, err
+	}
+	c.genres = &GenreCatalog{Checksum: sum.Checksum, Categories: resp.Categories}
+	return c.genres, nil
+}
+
+// CreateGenreStation adds the genre station best matching name, see
+// GenreCatalog.Lookup, to the user's stations.
+func (c *Client) CreateGenreStation(name string) (*response.StationCreateStation, error) {
+	catalog, err := c.GenreCatalog()
+	if err != nil {
+		return nil, err
+	}
+	m, err := catalog.Lookup(name)
+	if err != nil {
+		return nil, err
+	}
+	return c.StationCreateStationTrack(m.Station.StationToken, "song")
+}
+
+// Category returns the category best matching name.
+func (g *GenreCatalog) Category(name string) (*response.GenreCategory, error) {
+	q := normalizeGenre(name)
+	best, bestScore, tied := -1, 0, false
+	for i, cat := range g.Categories {
+		score := genreScore(q, normalizeGenre(cat.CategoryName))
+		switch {
+		case score > bestScore:
+			best, bestScore, tied = i, score, false
+		case score == bestScore && score > 0:
+			tied = true
+		}
+	}
+
+	if best < 0 {
+		return nil, fmt.Errorf("no genre category %q", name)
+	}
+	if tied && bestScore < scoreExact {
+		return nil, fmt.Errorf("genre category %q is ambiguous", name)
+	}
+	return &g.Categories[best], nil
+}
+
+// Find returns the genre stations matching name, best matches first.
+// Names are compared ignoring case and punctuation, and match if they are
+// equal, start with name, contain its words or differ by a typo.
+func (g *GenreCatalog) Find(name string) []GenreMatch {
+	q := normalizeGenre(name)
+
+	type scored struct {
+		GenreMatch
+		score int
+	}
+	var found []scored
+	seen := make(map[string]bool)
+	for _, cat := range g.Categories {
+		for _, s := range cat.Stations {
+			if seen[s.StationToken] {
+				// Stations can be listed in several categories.
+				continue
+			}
+			if score := genreScore(q, normalizeGenre(s.StationName)); score > 0 {
+				seen[s.StationToken] = true
+				found = append(found, scored{GenreMatch{cat.CategoryName, s}, score})
+			}
+		}
+	}
+	sort.SliceStable(found, func(i, j int) bool {
+		return found[i].score > found[j].score
+	})
+
+	matches := make([]GenreMatch, len(found))
+	for i, f := range found {
+		matches[i] = f.GenreMatch
+	}
+	return matches
+}
+
+// Lookup returns the genre station best matching name, see Find. It fails
+// if several stations match equally well, unless one is named exactly name.
+func (g *GenreCatalog) Lookup(name string) (GenreMatch, error) {
+	matches := g.Find(name)
+	if len(matches) == 0 {
+		return GenreMatch{}, fmt.Errorf("no genre station %q", name)
+	}
+
+	q := normalizeGenre(name)
+	best := genreScore(q, normalizeGenre(matches[0].Station.StationName))
+	var names []string
+	for _, m := range matches {
+		if genreScore(q, normalizeGenre(m.Station.StationName)) < best {
+			break
+		}
+		names = append(names, m.Station.StationName)
+	}
+	if len(names) > 1 && best < scoreExact {
+		return GenreMatch{}, fmt.Errorf("genre station %q is ambiguous: %s", name, strings.Join(names, ", "))
+	}
+	return matches[0], nil
+}
+
+// How well a name matches, see genreScore.
+const (
+	scoreTypo = 1 + iota
+	scoreWords
+	scorePrefix
+	scoreExact
+)
+
+// genreScore rates how well the normalized name n matches the normalized
+// query q, 0 meaning not at all.
+func genreScore(q, n string) int {
+	switch {
+	case q == "":
+		return 0
+	case q == n:
+		return scoreExact
+	case strings.HasPrefix(n, q):
+		return scorePrefix
+	}
+
+	words := strings.Fields(n)
+	all := true
+	for _, qw := range strings.Fields(q) {
+		found := false
+		for _, w := range words {
+			if strings.HasPrefix(w, qw) {
+				found = true
+				break
+			}
+		}
+		all = all && found
+	}
+	if all {
+		return scoreWords
+	}
+
+	maxTypos := len([]rune(q)) / 4
+	if maxTypos < 1 {
+		maxTypos = 1
+	}
+	if levenshtein(q, n) <= maxTypos {
+		return scoreTypo
+	}
+	return 0
+}
+
+// normalizeGenre lowercases a name, spells out "&" and reduces
+// everything else that is not a letter or digit to single spaces.
+func normalizeGenre(name string) string {
+	name = strings.ReplaceAll(strings.ToLower(name), "&", " and ")
+	return strings.Join(strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), " ")
+}
+
+// levenshtein returns the number of runes to insert, delete or replace to
+// turn a into b.
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
@@ -0,0 +1,115 @@
+package gopiano
+
+import (
+	"testing"
+
+	"denniskupec.com/gopiano/request"
+	"denniskupec.com/gopiano/response"
+)
+
+var testGenres = []response.GenreCategory{
+	{CategoryName: "Jazz", Stations: []response.GenreStation{
+		{StationToken: "g1", StationName: "Smooth Jazz"},
+		{StationToken: "g2", StationName: "Jazz Vocals"},
+		{StationToken: "g3", StationName: "Jazz"},
+	}},
+	{CategoryName: "R&B / Soul", Stations: []response.GenreStation{
+		{StationToken: "g4", StationName: "Classic R&B"},
+		{StationToken: "g5", StationName: "Soul Classics"},
+	}},
+	{CategoryName: "Today's Hits", Stations: []response.GenreStation{
+		{StationToken: "g1", StationName: "Smooth Jazz"},
+		{StationToken: "g6", StationName: "Today's Hits"},
+	}},
+}
+
+func TestGenreLookup(t *testing.T) {
+	g := &GenreCatalog{Categories: testGenres}
+
+	data := []struct {
+		Name, Token string
+	}{
+		{"smooth jazz", "g1"},
+		{"  SMOOTH-JAZZ ", "g1"},
+		{"smoth jazz", "g1"},
+		{"smooth", "g1"},
+		{"jazz", "g3"},
+		{"vocals", "g2"},
+		{"classic r and b", "g4"},
+		{"todays hits", "g6"},
+		{"classic", "g4"},
+		{"j", ""},
+		{"polka", ""},
+		{"", ""},
+	}
+	for _, d := range data {
+		m, err := g.Lookup(d.Name)
+		if d.Token == "" {
+			if err == nil {
+				t.Errorf("%q: expected error, got %q", d.Name, m.Station.StationName)
+			}
+			continue
+		}
+		if err != nil || m.Station.StationToken != d.Token {
+			t.Errorf("%q: expected %q, got %q, %v", d.Name, d.Token, m.Station.StationToken, err)
+		}
+	}
+
+	if n := len(g.Find("smooth jazz")); n != 1 {
+		t.Errorf("expected station listed twice to be found once, got %d", n)
+	}
+
+	cats := []struct {
+		Name, Category string
+	}{
+		{"r&b soul", "R&B / Soul"},
+		{"jaz", "Jazz"},
+		{"today", "Today's Hits"},
+		{"rock", ""},
+	}
+	for _, d := range cats {
+		cat, err := g.Category(d.Name)
+		if d.Category == "" {
+			if err == nil {
+				t.Errorf("%q: expected error, got %q", d.Name, cat.CategoryName)
+			}
+		} else if err != nil || cat.CategoryName != d.Category {
+			t.Errorf("%q: expected %q, got %v", d.Name, d.Category, err)
+		}
+	}
+}
+
+func TestGenreCatalogCache(t *testing.T) {
+	checksum, fetches := "a", 0
+	var created request.CreateStation
+	c, _ := NewClient(AndroidClient)
+	c.Interceptors = []Interceptor{func(req request.Type, data interface{}, next Invoker) error {
+		switch r := req.(type) {
+		case request.GetGenreStationsChecksum:
+			data.(*response.StationGetGenreStationsChecksum).Checksum = checksum
+		case request.GetGenreStations:
+			fetches++
+			data.(*response.StationGetGenreStations).Categories = testGenres
+		case request.CreateStation:
+			created = r
+		}
+		return nil
+	}}
+
+	c.GenreCatalog()
+	c.GenreCatalog()
+	if fetches != 1 {
+		t.Errorf("expected 1 fetch, got %d", fetches)
+	}
+	checksum = "b"
+	if g, _ := c.GenreCatalog(); fetches != 2 || g.Checksum != "b" {
+		t.Errorf("expected refetch after checksum change, got %d fetches", fetches)
+	}
+
+	if _, err := c.CreateGenreStation("Smooth Jazz"); err != nil {
+		t.Fatal(err)
+	}
+	if created.TrackToken != "g1" || created.MusicType != "song" {
+		t.Errorf("unexpected request %+v", created)
+	}
+}
@@ -13,11 +13,14 @@ by these client methods.
 package gopiano
 
 import (
-	"encoding/hex"
+	"bytes"
 	"encoding/json"
 	"io"
+	"io/ioutil"
+	"log/slog"
 	"net/http"
 	"net/url"
+	"sync"
 	"time"
 
 	"golang.org/x/crypto/blowfish"
@@ -40,14 +43,55 @@ type ClientDescription struct {
 
 // Client information needed to interface with pandora API.
 type Client struct {
-	description      ClientDescription
-	encrypter        *blowfish.Cipher
-	decrypter        *blowfish.Cipher
+	description ClientDescription
+	encrypter   *blowfish.Cipher
+	decrypter   *blowfish.Cipher
+
+	// sessionMu guards the session state below, which logins and resyncs
+	// change while other calls may be using it.
+	sessionMu        sync.Mutex
 	timeOffset       time.Duration
+	lastSync         time.Time
+	resyncing        bool
 	partnerAuthToken string
 	partnerID        string
 	userAuthToken    string
 	userID           string
+	username         string
+	password         string
+
+	quickMixMu sync.Mutex
+
+	genreMu sync.Mutex
+	genres  *GenreCatalog // cached, see GenreCatalog
+
+	// Clock returns the current time. If nil, time.Now is used.
+	Clock func() time.Time
+
+	// ResyncInterval, if positive, is how often the offset to Pandora's
+	// clock is measured again, see Resync. The first call made after the
+	// interval has passed triggers the resync; calls made at the same time
+	// from other goroutines go on with the old offset.
+	ResyncInterval time.Duration
+
+	// HTTPClient is used to talk to Pandora. If nil, http.DefaultClient is used.
+	HTTPClient *http.Client
+
+	// Metrics, if set, is told about every call.
+	Metrics Metrics
+
+	// Interceptors wrap every call, including the partner login. The first
+	// one is outermost and sees calls before all the others.
+	Interceptors []Interceptor
+
+	// OnSchemaDrift, if set, enables strict mode: the result of every call
+	// is compared with its response type and any unknown or missing fields
+	// are reported, so changes of the API are noticed early.
+	OnSchemaDrift func(response.SchemaDrift)
+
+	// Logger, if set, logs every call: successful ones at debug level,
+	// failed ones as warnings. Passwords and auth tokens are redacted.
+	Logger *slog.Logger
 }
 
 // NewClient creates a new Client with specified ClientDescription
@@ -73,26 +117,17 @@ func NewClient(d ClientDescription) (*Client, error) {
 //
 // Decryption is done inplace and returns the number of bytes successfully decrypted.
 func (c *Client) decrypt(data []byte) (n int, err error) {
-	for in, out := data, data; 16 <= len(in); in, out = in[16:], out[8:] {
-		if _, err := hex.Decode(out[:8], in[:16]); err != nil {
-			return n, err
-		}
-
-		c.decrypter.Decrypt(out[:8], out[:8])
-
-		n += 8
-	}
-
-	for 0 < n && data[n-1] == '\x00' {
-		n--
-	}
-
-	return n, nil
+	out, err := ioutil.ReadAll(coder.NewDecoder(c.decrypter, bytes.NewReader(data)))
+	return copy(data, out), err
 }
 
 // PandoraCall is the basic function to send an HTTP POST to pandora.com.
 func PandoraCall(callURL string, body io.Reader) (json.RawMessage, error) {
-	resp, err := http.Post(callURL, "text/plain", body)
+	return pandoraCall(http.DefaultClient, callURL, body)
+}
+
+func pandoraCall(client *http.Client, callURL string, body io.Reader) (json.RawMessage, error) {
+	resp, err := client.Post(callURL, "text/plain", body)
 	if err != nil {
 		return nil, err
 	}
@@ -119,6 +154,13 @@ func (c *Client) formatURL(req request.Type) string {
 		"method": {req.Method()},
 	}
 
+	// A new partner login, e.g. by Resync, must not carry the old session.
+	if _, ok := req.(request.PartnerLogin); ok {
+		return req.Protocol().URL() + c.description.BaseURL + "?" + urlArgs.Encode()
+	}
+
+	c.sessionMu.Lock()
+	defer c.sessionMu.Unlock()
 	if c.partnerID != "" {
 		urlArgs.Add("partner_id", c.partnerID)
 	}
@@ -135,31 +177,90 @@ func (c *Client) formatURL(req request.Type) string {
 }
 
 // Call makes the given request to pandora and unmarshals the result into
-// the 'data' argument.
+// the 'data' argument. The call goes through the client's Interceptors.
 func (c *Client) Call(req request.Type, data interface{}) error {
-	enc := coder.New(c.encrypter)
-	if err := json.NewEncoder(enc).Encode(req); err != nil {
-		return err
-	}
+	err := c.intercept(c.call)(req, data)
+	c.maybeResync()
+	return err
+}
+
+// call is the Invoker doing the actual work of Call.
+func (c *Client) call(req request.Type, data interface{}) (err error) {
+	callURL := c.formatURL(req)
+	start := time.Now()
+	defer func() { c.finishCall(req, callURL, start, err) }()
 
-	res, err := PandoraCall(c.formatURL(req), enc)
+	// The request is encrypted while it is sent.
+	pr, pw := io.Pipe()
+	encErr := make(chan error, 1)
+	go func() {
+		enc := coder.NewEncoder(c.encrypter, pw)
+		err := json.NewEncoder(enc).Encode(req)
+		if cerr := enc.Close(); err == nil {
+			err = cerr
+		}
+		pw.CloseWithError(err)
+		encErr <- err
+	}()
+
+	res, err := pandoraCall(c.httpClient(), callURL, pr)
+	// The pipe is closed if the request failed early, stopping the encoder.
+	if e := <-encErr; e != nil && e != io.ErrClosedPipe {
+		return e
+	}
 	if err != nil {
 		return err
 	}
 
+	c.checkSchema(req, res, data)
 	return json.Unmarshal(res, data)
 }
 
+// checkSchema reports the differences between a call's result and its
+// response type in strict mode.
+func (c *Client) checkSchema(req request.Type, res json.RawMessage, data interface{}) {
+	if c.OnSchemaDrift == nil {
+		return
+	}
+	if d := response.CheckSchema(req.Method(), res, data); d != nil {
+		c.OnSchemaDrift(*d)
+	}
+}
+
+func (c *Client) httpClient() *http.Client {
+	if c.HTTPClient != nil {
+		return c.HTTPClient
+	}
+	return http.DefaultClient
+}
+
+// finishCall reports a call to Metrics and Logger.
+func (c *Client) finishCall(req request.Type, callURL string, start time.Time, err error) {
+	d := time.Since(start)
+	if c.Metrics != nil {
+		c.Metrics.ObserveCall(req.Method(), d, err)
+	}
+	if c.Logger != nil {
+		c.logCall(req, callURL, d, err)
+	}
+}
+
 // GetSyncTime returns a calculated SyncTime (Unix epoch) which is required
 // for most calls.
 func (c *Client) GetSyncTime() int {
-	return int(time.Now().Add(c.timeOffset).Unix())
+	now := c.now()
+	c.sessionMu.Lock()
+	defer c.sessionMu.Unlock()
+	return int(now.Add(c.timeOffset).Unix())
 }
 
 // Token returns UserToken needed for some requests
 func (c *Client) Token() request.UserToken {
+	syncTime := c.GetSyncTime()
+	c.sessionMu.Lock()
+	defer c.sessionMu.Unlock()
 	return request.UserToken{
 		UserAuthToken: c.userAuthToken,
-		SyncTime:      c.GetSyncTime(),
+		SyncTime:      syncTime,
 	}
 }
@@ -3,6 +3,7 @@ package gopiano
 import (
 	"bytes"
 	"flag"
+	"os"
 	"testing"
 )
 
@@ -12,9 +13,14 @@ var pUsername, pPassword string
 func init() {
 	flag.StringVar(&pUsername, "username", "", "Pandora login username")
 	flag.StringVar(&pPassword, "password", "", "Pandora login password")
+}
+
+func TestMain(m *testing.M) {
 	flag.Parse()
 
 	client, _ = NewClient(AndroidClient)
+
+	os.Exit(m.Run())
 }
 
 func Test_Decrypt_1(t *testing.T) {
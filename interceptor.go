@@ -0,0 +1,32 @@
+package gopiano
+
+import (
+	"denniskupec.com/gopiano/request"
+)
+
+// Invoker performs a call, unmarshaling its result into data.
+type Invoker func(req request.Type, data interface{}) error
+
+// Interceptor wraps the calls of a Client, for example to cache, trace or
+// audit them. It is given the request, the value the result is to be
+// unmarshaled into, and next, which continues the call. An interceptor
+// may change the request before passing it on, fill in data itself
+// without calling next, call next several times to retry, and inspect or
+// replace the returned error, which is a response.ErrorResponse if Pandora
+// refused the call.
+//
+// Metrics and Logger see each call as it reaches Pandora, after all
+// interceptors.
+type Interceptor func(req request.Type, data interface{}, next Invoker) error
+
+// intercept wraps inner in the client's interceptors.
+func (c *Client) intercept(inner Invoker) Invoker {
+	next := inner
+	for i := len(c.Interceptors) - 1; i >= 0; i-- {
+		ic, n := c.Interceptors[i], next
+		next = func(req request.Type, data interface{}) error {
+			return ic(req, data, n)
+		}
+	}
+	return next
+}
@@ -0,0 +1,104 @@
+package gopiano
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"golang.org/x/crypto/blowfish"
+
+	"denniskupec.com/gopiano/request"
+	"denniskupec.com/gopiano/response"
+)
+
+func TestInterceptors(t *testing.T) {
+	c, _ := NewClient(AndroidClient)
+
+	var order []string
+	trace := func(name string) Interceptor {
+		return func(req request.Type, data interface{}, next Invoker) error {
+			order = append(order, name+" "+req.Method())
+			return next(req, data)
+		}
+	}
+
+	// Fails the first call, to be retried by the interceptor before it.
+	failed := false
+	flaky := func(req request.Type, data interface{}, next Invoker) error {
+		if !failed {
+			failed = true
+			return response.ErrorResponse{Code: 0}
+		}
+		return next(req, data)
+	}
+	retry := func(req request.Type, data interface{}, next Invoker) error {
+		err := next(req, data)
+		if _, ok := err.(response.ErrorResponse); ok {
+			err = next(req, data)
+		}
+		return err
+	}
+
+	// Answers without reaching Pandora.
+	var seen request.Type
+	fake := func(req request.Type, data interface{}, next Invoker) error {
+		seen = req
+		return json.Unmarshal([]byte(`{"explanations": [{"focusTraitName": "twang"}]}`), data)
+	}
+
+	// Rewrites the request.
+	rewrite := func(req request.Type, data interface{}, next Invoker) error {
+		r := req.(request.ExplainTrack)
+		r.TrackToken = "rewritten"
+		return next(r, data)
+	}
+
+	c.Interceptors = []Interceptor{trace("outer"), retry, trace("inner"), flaky, rewrite, fake}
+
+	resp, err := c.ExplainTrack("token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Explanations) != 1 || resp.Explanations[0].FocusTraitName != "twang" {
+		t.Errorf("unexpected response %+v", resp)
+	}
+	if r, ok := seen.(request.ExplainTrack); !ok || r.TrackToken != "rewritten" {
+		t.Errorf("unexpected request %+v", seen)
+	}
+	expected := []string{"outer track.explainTrack", "inner track.explainTrack", "inner track.explainTrack"}
+	if !reflect.DeepEqual(order, expected) {
+		t.Errorf("\nexpected:\n\t%q\ngot:\n\t%q", expected, order)
+	}
+}
+
+func TestInterceptPartnerLogin(t *testing.T) {
+	c, _ := NewClient(AndroidClient)
+
+	// Pandora sends the sync time encrypted, after 4 garbage bytes.
+	plain := []byte("abcd1600000000\x00\x00")
+	cipher, _ := blowfish.NewCipher([]byte(AndroidClient.DecryptKey))
+	for i := 0; i < len(plain); i += 8 {
+		cipher.Encrypt(plain[i:i+8], plain[i:i+8])
+	}
+
+	c.Interceptors = []Interceptor{func(req request.Type, data interface{}, next Invoker) error {
+		if _, ok := req.(request.PartnerLogin); !ok {
+			t.Errorf("unexpected request %T", req)
+		}
+		*data.(*response.AuthPartnerLogin) = response.AuthPartnerLogin{
+			PartnerID:        "42",
+			PartnerAuthToken: "token",
+			SyncTime:         hex.EncodeToString(plain),
+		}
+		return nil
+	}}
+
+	resp, err := c.AuthPartnerLogin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.SyncTime != "1600000000" || c.partnerID != "42" || c.partnerAuthToken != "token" {
+		t.Errorf("unexpected partner login %+v", resp)
+	}
+}
@@ -0,0 +1,93 @@
+package gopiano
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/url"
+	"time"
+
+	"denniskupec.com/gopiano/request"
+	"denniskupec.com/gopiano/response"
+)
+
+// Redacted replaces secrets in logged requests and URLs.
+const Redacted = "REDACTED"
+
+// Request fields and URL query parameters that hold secrets.
+var secretFields = map[string]bool{
+	"password":         true,
+	"partnerAuthToken": true,
+	"userAuthToken":    true,
+	"auth_token":       true,
+}
+
+func (c *Client) logCall(req request.Type, callURL string, d time.Duration, err error) {
+	attrs := []slog.Attr{
+		slog.String("method", req.Method()),
+		slog.String("protocol", req.Protocol().String()),
+		slog.Duration("duration", d),
+		slog.Any("request", redactedRequest{req}),
+		slog.String("url", RedactURL(callURL)),
+	}
+
+	if err == nil {
+		c.Logger.LogAttrs(context.Background(), slog.LevelDebug, "pandora call", attrs...)
+		return
+	}
+	if e, ok := err.(response.ErrorResponse); ok {
+		attrs = append(attrs, slog.Int("code", e.Code))
+	}
+	attrs = append(attrs, slog.String("error", err.Error()))
+	c.Logger.LogAttrs(context.Background(), slog.LevelWarn, "pandora call failed", attrs...)
+}
+
+// redactedRequest logs a request as JSON with secrets removed. The work is
+// only done if the record is actually logged.
+type redactedRequest struct {
+	req request.Type
+}
+
+func (r redactedRequest) LogValue() slog.Value {
+	return slog.StringValue(RedactRequest(r.req))
+}
+
+// RedactRequest returns the JSON encoding of req with passwords and auth
+// tokens replaced by Redacted.
+func RedactRequest(req request.Type) string {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return err.Error()
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err.Error()
+	}
+	for name := range fields {
+		if secretFields[name] {
+			fields[name] = Redacted
+		}
+	}
+
+	data, _ = json.Marshal(fields)
+	return string(data)
+}
+
+// RedactURL replaces the values of auth token parameters in a URL such as
+// those Client sends requests to.
+func RedactURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return Redacted
+	}
+
+	q := u.Query()
+	for name := range q {
+		if secretFields[name] {
+			q.Set(name, Redacted)
+		}
+	}
+	u.RawQuery = q.Encode()
+	return u.String()
+}
@@ -0,0 +1,59 @@
+package gopiano
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"denniskupec.com/gopiano/request"
+	"denniskupec.com/gopiano/response"
+)
+
+func TestRedact(t *testing.T) {
+	req := request.UserLogin{
+		PartnerAuthToken: "partner-secret",
+		LoginType:        "user",
+		Username:         "user@example.com",
+		Password:         "password-secret",
+	}
+	got := RedactRequest(req)
+	if strings.Contains(got, "secret") || !strings.Contains(got, "user@example.com") {
+		t.Errorf("bad redaction: %s", got)
+	}
+
+	playlist := request.GetPlaylist{
+		UserToken:    request.UserToken{UserAuthToken: "user-secret", SyncTime: 1},
+		StationToken: "123",
+	}
+	got = RedactRequest(playlist)
+	if strings.Contains(got, "secret") || !strings.Contains(got, `"stationToken":"123"`) {
+		t.Errorf("bad redaction: %s", got)
+	}
+
+	got = RedactURL("https://tuner.pandora.com/services/json/?auth_token=abc%2Bsecret&method=station.getPlaylist&user_id=42")
+	expected := "https://tuner.pandora.com/services/json/?auth_token=REDACTED&method=station.getPlaylist&user_id=42"
+	if got != expected {
+		t.Errorf("\nexpected:\n\t%q\ngot:\n\t%q", expected, got)
+	}
+}
+
+func TestLogCall(t *testing.T) {
+	var buf bytes.Buffer
+	c, _ := NewClient(AndroidClient)
+	c.Logger = slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	req := request.UserLogin{PartnerAuthToken: "partner-secret", Password: "password-secret"}
+	c.logCall(req, "https://example.com/?auth_token=secret", time.Second, response.ErrorResponse{Code: 1002})
+
+	out := buf.String()
+	if strings.Contains(out, "secret") {
+		t.Errorf("secret logged: %s", out)
+	}
+	for _, s := range []string{"level=WARN", "method=auth.userLogin", "protocol=https", "duration=1s", "code=1002"} {
+		if !strings.Contains(out, s) {
+			t.Errorf("missing %q in: %s", s, out)
+		}
+	}
+}
@@ -0,0 +1,18 @@
+package gopiano
+
+import (
+	"time"
+)
+
+// Metrics receives measurements of a Client's API calls, e.g. to export them
+// for monitoring. Package metrics has an implementation. Methods may be
+// called from several goroutines at once.
+type Metrics interface {
+	// ObserveCall is called after every API call with the Pandora method
+	// name, such as "station.getPlaylist", how long the call took and its
+	// error, which is a response.ErrorResponse if Pandora refused the call.
+	ObserveCall(method string, d time.Duration, err error)
+
+	// ObserveRelogin is called whenever Relogin logs in again.
+	ObserveRelogin()
+}
@@ -0,0 +1,181 @@
+/*
+Package metrics records the API calls of a gopiano.Client and exports them
+in the Prometheus text format.
+
+	m := metrics.New()
+	client.Metrics = m
+	http.Handle("/metrics", m)
+
+The following metrics are exported:
+
+	gopiano_calls_total{method}                  calls per Pandora method
+	gopiano_call_errors_total{method,code}       failed calls, by Pandora error code or "other"
+	gopiano_call_duration_seconds{method}        histogram of call latency
+	gopiano_relogins_total                       sessions renewed by Client.Relogin
+*/
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"denniskupec.com/gopiano/response"
+)
+
+// DefaultBuckets are the upper bounds, in seconds, of the latency histogram
+// buckets used by New if none are given.
+var DefaultBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// Recorder collects metrics. It implements gopiano.Metrics and serves the
+// collected metrics over HTTP.
+type Recorder struct {
+	buckets []float64
+
+	mu       sync.Mutex
+	methods  map[string]*method
+	relogins uint64
+}
+
+type method struct {
+	calls  uint64
+	errors map[string]uint64 // by error code
+	counts []uint64          // per bucket, not cumulative; the last is +Inf
+	sum    float64
+}
+
+// New returns a Recorder using the given histogram buckets, or DefaultBuckets.
+func New(buckets ...float64) *Recorder {
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets
+	}
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+
+	return &Recorder{
+		buckets: buckets,
+		methods: make(map[string]*method),
+	}
+}
+
+// ObserveCall records a call.
+func (r *Recorder) ObserveCall(name string, d time.Duration, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	m := r.methods[name]
+	if m == nil {
+		m = &method{
+			errors: make(map[string]uint64),
+			counts: make([]uint64, len(r.buckets)+1),
+		}
+		r.methods[name] = m
+	}
+
+	m.calls++
+	if err != nil {
+		code := "other"
+		if e, ok := err.(response.ErrorResponse); ok {
+			code = strconv.Itoa(e.Code)
+		}
+		m.errors[code]++
+	}
+
+	s := d.Seconds()
+	m.sum += s
+	m.counts[sort.SearchFloat64s(r.buckets, s)]++
+}
+
+// ObserveRelogin records a relogin.
+func (r *Recorder) ObserveRelogin() {
+	r.mu.Lock()
+	r.relogins++
+	r.mu.Unlock()
+}
+
+// ServeHTTP writes the metrics in the Prometheus text format.
+func (r *Recorder) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	r.WriteTo(w)
+}
+
+// WriteTo writes the metrics in the Prometheus text format.
+func (r *Recorder) WriteTo(w io.Writer) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	names := make([]string, 0, len(r.methods))
+	for name := range r.methods {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	cw := &countWriter{w: bufio.NewWriter(w)}
+
+	fmt.Fprint(cw, "# HELP gopiano_calls_total Pandora API calls.\n# TYPE gopiano_calls_total counter\n")
+	for _, name := range names {
+		fmt.Fprintf(cw, "gopiano_calls_total{method=%s} %d\n", quote(name), r.methods[name].calls)
+	}
+
+	fmt.Fprint(cw, "# HELP gopiano_call_errors_total Failed Pandora API calls by error code.\n# TYPE gopiano_call_errors_total counter\n")
+	for _, name := range names {
+		m := r.methods[name]
+		codes := make([]string, 0, len(m.errors))
+		for code := range m.errors {
+			codes = append(codes, code)
+		}
+		sort.Strings(codes)
+		for _, code := range codes {
+			fmt.Fprintf(cw, "gopiano_call_errors_total{method=%s,code=%s} %d\n", quote(name), quote(code), m.errors[code])
+		}
+	}
+
+	fmt.Fprint(cw, "# HELP gopiano_call_duration_seconds Latency of Pandora API calls.\n# TYPE gopiano_call_duration_seconds histogram\n")
+	for _, name := range names {
+		m := r.methods[name]
+		var n uint64
+		for i, le := range r.buckets {
+			n += m.counts[i]
+			fmt.Fprintf(cw, "gopiano_call_duration_seconds_bucket{method=%s,le=%s} %d\n",
+				quote(name), quote(strconv.FormatFloat(le, 'g', -1, 64)), n)
+		}
+		fmt.Fprintf(cw, "gopiano_call_duration_seconds_bucket{method=%s,le=\"+Inf\"} %d\n", quote(name), m.calls)
+		fmt.Fprintf(cw, "gopiano_call_duration_seconds_sum{method=%s} %g\n", quote(name), m.sum)
+		fmt.Fprintf(cw, "gopiano_call_duration_seconds_count{method=%s} %d\n", quote(name), m.calls)
+	}
+
+	fmt.Fprint(cw, "# HELP gopiano_relogins_total Sessions renewed after the auth token expired.\n# TYPE gopiano_relogins_total counter\n")
+	fmt.Fprintf(cw, "gopiano_relogins_total %d\n", r.relogins)
+
+	if cw.err != nil {
+		return cw.n, cw.err
+	}
+	return cw.n, cw.w.Flush()
+}
+
+// quote quotes a label value.
+func quote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s) + `"`
+}
+
+type countWriter struct {
+	w   *bufio.Writer
+	n   int64
+	err error
+}
+
+func (cw *countWriter) Write(p []byte) (int, error) {
+	if cw.err != nil {
+		return 0, cw.err
+	}
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	cw.err = err
+	return n, err
+}
@@ -0,0 +1,46 @@
+package metrics
+
+import (
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"denniskupec.com/gopiano/response"
+)
+
+func TestRecorder(t *testing.T) {
+	r := New(0.1, 1)
+	r.ObserveCall("station.getPlaylist", 50*time.Millisecond, nil)
+	r.ObserveCall("station.getPlaylist", 500*time.Millisecond, response.ErrorResponse{Code: 1001})
+	r.ObserveCall("station.getPlaylist", 2*time.Second, errors.New("timeout"))
+	r.ObserveCall("auth.userLogin", 100*time.Millisecond, nil)
+	r.ObserveRelogin()
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	out := w.Body.String()
+
+	expected := []string{
+		`gopiano_calls_total{method="auth.userLogin"} 1`,
+		`gopiano_calls_total{method="station.getPlaylist"} 3`,
+		`gopiano_call_errors_total{method="station.getPlaylist",code="1001"} 1`,
+		`gopiano_call_errors_total{method="station.getPlaylist",code="other"} 1`,
+		`gopiano_call_duration_seconds_bucket{method="auth.userLogin",le="0.1"} 1`,
+		`gopiano_call_duration_seconds_bucket{method="station.getPlaylist",le="0.1"} 1`,
+		`gopiano_call_duration_seconds_bucket{method="station.getPlaylist",le="1"} 2`,
+		`gopiano_call_duration_seconds_bucket{method="station.getPlaylist",le="+Inf"} 3`,
+		`gopiano_call_duration_seconds_sum{method="station.getPlaylist"} 2.55`,
+		`gopiano_call_duration_seconds_count{method="station.getPlaylist"} 3`,
+		`gopiano_relogins_total 1`,
+	}
+	for _, line := range expected {
+		if !strings.Contains(out, line+"\n") {
+			t.Errorf("missing line %q in:\n%s", line, out)
+		}
+	}
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("unexpected content type %q", ct)
+	}
+}
@@ -0,0 +1,200 @@
+package gopiano
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"denniskupec.com/gopiano/request"
+	"denniskupec.com/gopiano/response"
+)
+
+// QuickMix manages which stations are played by the special QuickMix
+// station, called Shuffle by newer clients.
+//
+// Pandora only allows replacing the whole set, so every change reads the
+// current set first. Changes through the QuickMix values of one Client are
+// serialized, which makes it safe to toggle single stations concurrently.
+//
+// Stations are identified by their token, ID or name, ignoring case.
+type QuickMix struct {
+	c *Client
+
+	// Shuffle requests the station list with includeShuffleInsteadOfQuickMix,
+	// so that the QuickMix station is named "Shuffle".
+	Shuffle bool
+}
+
+// QuickMix returns a QuickMix for the user's account.
+func (c *Client) QuickMix() *QuickMix {
+	return &QuickMix{c: c}
+}
+
+// Station returns the QuickMix station itself.
+func (q *QuickMix) Station() (*response.Station, error) {
+	_, qm, err := q.load()
+	return qm, err
+}
+
+// Members returns the stations in the QuickMix.
+func (q *QuickMix) Members() (response.StationList, error) {
+	list, qm, err := q.load()
+	if err != nil {
+		return nil, err
+	}
+
+	var members response.StationList
+	for _, id := range qm.QuickMixStationIDs {
+		if s := findStation(list, id); s != nil {
+			members = append(members, *s)
+		}
+	}
+	return members, nil
+}
+
+// Contains reports whether station is in the QuickMix.
+func (q *QuickMix) Contains(station string) (bool, error) {
+	list, qm, err := q.load()
+	if err != nil {
+		return false, err
+	}
+
+	s := findStation(list, station)
+	if s == nil {
+		return false, fmt.Errorf("no station %q", station)
+	}
+	return indexOf(qm.QuickMixStationIDs, s.StationID) >= 0, nil
+}
+
+// Set replaces the stations in the QuickMix.
+func (q *QuickMix) Set(stations ...string) error {
+	return q.update(func(ids []string, list response.StationList) ([]string, error) {
+		return resolveStations(list, stations)
+	})
+}
+
+// Add puts stations into the QuickMix.
+func (q *QuickMix) Add(stations ...string) error {
+	return q.update(func(ids []string, list response.StationList) ([]string, error) {
+		add, err := resolveStations(list, stations)
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range add {
+			if indexOf(ids, id) < 0 {
+				ids = append(ids, id)
+			}
+		}
+		return ids, nil
+	})
+}
+
+// Remove takes stations out of the QuickMix.
+func (q *QuickMix) Remove(stations ...string) error {
+	return q.update(func(ids []string, list response.StationList) ([]string, error) {
+		remove, err := resolveStations(list, stations)
+		if err != nil {
+			return nil, err
+		}
+		var keep []string
+		for _, id := range ids {
+			if indexOf(remove, id) < 0 {
+				keep = append(keep, id)
+			}
+		}
+		return keep, nil
+	})
+}
+
+// Toggle adds station to the QuickMix if it is not in it and removes it otherwise.
+// It returns whether the station is in the QuickMix afterwards.
+func (q *QuickMix) Toggle(station string) (in bool, err error) {
+	err = q.update(func(ids []string, list response.StationList) ([]string, error) {
+		s := findStation(list, station)
+		if s == nil {
+			return nil, fmt.Errorf("no station %q", station)
+		}
+
+		if i := indexOf(ids, s.StationID); i >= 0 {
+			return append(ids[:i:i], ids[i+1:]...), nil
+		}
+		in = true
+		return append(ids, s.StationID), nil
+	})
+	return in, err
+}
+
+func (q *QuickMix) update(change func(ids []string, list response.StationList) ([]string, error)) error {
+	q.c.quickMixMu.Lock()
+	defer q.c.quickMixMu.Unlock()
+
+	list, qm, err := q.load()
+	if err != nil {
+		return err
+	}
+
+	ids, err := change(append([]string(nil), qm.QuickMixStationIDs...), list)
+	if err != nil {
+		return err
+	}
+	if ids == nil {
+		ids = []string{}
+	}
+
+	return q.c.UserSetQuickMix(ids)
+}
+
+func (q *QuickMix) load() (response.StationList, *response.Station, error) {
+	requestData := request.GetStationList{
+		IncludeShuffleInsteadOfQuickMix: q.Shuffle,
+		UserToken:                       q.c.Token(),
+	}
+
+	var resp response.UserGetStationList
+	if err := q.c.Call(requestData, &resp); err != nil {
+		return nil, nil, err
+	}
+
+	for i := range resp.Stations {
+		if resp.Stations[i].IsQuickMix {
+			return resp.Stations, &resp.Stations[i], nil
+		}
+	}
+	return nil, nil, errors.New("account has no QuickMix station")
+}
+
+// findStation looks up a station other than the QuickMix by token, ID or name.
+func findStation(list response.StationList, station string) *response.Station {
+	for i, s := range list {
+		if !s.IsQuickMix && (s.StationToken == station || s.StationID == station) {
+			return &list[i]
+		}
+	}
+	for i, s := range list {
+		if !s.IsQuickMix && strings.EqualFold(s.StationName, strings.TrimSpace(station)) {
+			return &list[i]
+		}
+	}
+	return nil
+}
+
+func resolveStations(list response.StationList, stations []string) ([]string, error) {
+	ids := make([]string, 0, len(stations))
+	for _, station := range stations {
+		s := findStation(list, station)
+		if s == nil {
+			return nil, fmt.Errorf("no station %q", station)
+		}
+		ids = append(ids, s.StationID)
+	}
+	return ids, nil
+}
+
+func indexOf(list []string, s string) int {
+	for i, x := range list {
+		if x == s {
+			return i
+		}
+	}
+	return -1
+}
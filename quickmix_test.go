@@ -0,0 +1,132 @@
+package gopiano
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"denniskupec.com/gopiano/request"
+	"denniskupec.com/gopiano/response"
+)
+
+func TestFindStation(t *testing.T) {
+	list := response.StationList{
+		{StationID: "1", StationToken: "t1", StationName: "Shuffle", IsQuickMix: true},
+		{StationID: "2", StationToken: "t2", StationName: "Jazz Radio"},
+		{StationID: "3", StationToken: "t3", StationName: "2"},
+	}
+
+	data := []struct {
+		Station, ID string
+	}{
+		{"t2", "2"},
+		{"2", "2"},
+		{"jazz radio", "2"},
+		{"t3", "3"},
+		{"Shuffle", ""},
+		{"t1", ""},
+	}
+	for _, d := range data {
+		s := findStation(list, d.Station)
+		if d.ID == "" && s != nil || d.ID != "" && (s == nil || s.StationID != d.ID) {
+			t.Errorf("%q: expected station %q, got %+v", d.Station, d.ID, s)
+		}
+	}
+
+	if _, err := resolveStations(list, []string{"t2", "missing"}); err == nil {
+		t.Error("expected error for unknown station")
+	}
+}
+
+// quickMixAccount fakes an account whose QuickMix holds ids, recording the
+// payload of every user.setQuickMix call.
+func quickMixAccount(t *testing.T, ids ...string) (*Client, *[]string) {
+	var sent []string
+	c, _ := NewClient(AndroidClient)
+	c.Interceptors = []Interceptor{func(req request.Type, data interface{}, next Invoker) error {
+		switch r := req.(type) {
+		case request.GetStationList:
+			data.(*response.UserGetStationList).Stations = response.StationList{
+				{StationID: "1", StationToken: "t1", StationName: "QuickMix", IsQuickMix: true, QuickMixStationIDs: ids},
+				{StationID: "2", StationToken: "t2", StationName: "Jazz"},
+				{StationID: "3", StationToken: "t3", StationName: "Rock"},
+				{StationID: "4", StationToken: "t4", StationName: "Blues"},
+			}
+		case request.SetQuickMix:
+			payload, err := json.Marshal(r.QuickMixStationIDs)
+			if err != nil {
+				t.Fatal(err)
+			}
+			sent = append(sent, string(payload))
+			ids = r.QuickMixStationIDs
+		default:
+			t.Errorf("unexpected %s", req.Method())
+		}
+		return nil
+	}}
+	return c, &sent
+}
+
+func TestQuickMix(t *testing.T) {
+	c, sent := quickMixAccount(t, "2")
+	q := c.QuickMix()
+
+	if err := q.Add("rock", "t2"); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.Remove("Jazz"); err != nil {
+		t.Fatal(err)
+	}
+	if in, err := q.Toggle("blues"); err != nil || !in {
+		t.Errorf("expected Blues to be toggled in, got %v, %v", in, err)
+	}
+	if in, err := q.Toggle("t3"); err != nil || in {
+		t.Errorf("expected Rock to be toggled out, got %v, %v", in, err)
+	}
+	if err := q.Set("Jazz", "Rock"); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.Set(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Unknown stations fail before anything is sent.
+	if err := q.Add("Polka"); err == nil {
+		t.Error("expected an error for an unknown station")
+	}
+	if _, err := q.Toggle("QuickMix"); err == nil {
+		t.Error("expected an error for toggling the QuickMix itself")
+	}
+
+	expected := []string{`["2","3"]`, `["3"]`, `["3","4"]`, `["4"]`, `["2","3"]`, `[]`}
+	if !reflect.DeepEqual(*sent, expected) {
+		t.Errorf("\nexpected:\n\t%q\ngot:\n\t%q", expected, *sent)
+	}
+}
+
+func TestQuickMixMembers(t *testing.T) {
+	c, sent := quickMixAccount(t, "3", "9", "2")
+	q := c.QuickMix()
+
+	members, err := q.Members()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, s := range members {
+		names = append(names, s.StationName)
+	}
+	if expected := []string{"Rock", "Jazz"}; !reflect.DeepEqual(names, expected) {
+		t.Errorf("\nexpected:\n\t%q\ngot:\n\t%q", expected, names)
+	}
+
+	if in, err := q.Contains("jazz"); err != nil || !in {
+		t.Errorf("expected Jazz to be in the QuickMix, got %v, %v", in, err)
+	}
+	if in, err := q.Contains("Blues"); err != nil || in {
+		t.Errorf("expected Blues not to be in the QuickMix, got %v, %v", in, err)
+	}
+	if len(*sent) != 0 {
+		t.Errorf("reading the QuickMix changed it: %q", *sent)
+	}
+}
@@ -0,0 +1,160 @@
+package gopiano
+
+import (
+	"errors"
+	"sync"
+
+	"denniskupec.com/gopiano/response"
+)
+
+// Rating is the user's opinion of a track.
+type Rating int
+
+// Ratings of a track. ThumbsDown is also called ban.
+const (
+	Unrated    Rating = 0
+	ThumbsUp   Rating = 1
+	ThumbsDown Rating = -1
+)
+
+func (r Rating) String() string {
+	switch {
+	case r > 0:
+		return "thumbs up"
+	case r < 0:
+		return "thumbs down"
+	}
+	return "unrated"
+}
+
+var (
+	// ErrNothingToUndo is returned by Ratings.Undo if no rating is left to undo.
+	ErrNothingToUndo = errors.New("no rating to undo")
+	// ErrNoFeedbackID is returned when a rating given outside of Ratings,
+	// e.g. by another client, is to be removed.
+	ErrNoFeedbackID = errors.New("rating was not given through this session")
+)
+
+// Ratings keeps the rating of the tracks of a listening session and the
+// feedback IDs of the ratings given, so they can be taken back.
+// It is safe for concurrent use.
+type Ratings struct {
+	c *Client
+
+	mu      sync.Mutex
+	tracks  map[string]trackRating // by track token
+	history []ratingChange         // for Undo, last change last
+}
+
+type trackRating struct {
+	stationToken string
+	rating       Rating
+	feedbackID   string // empty if not rated through Ratings
+}
+
+type ratingChange struct {
+	trackToken string
+	before     trackRating
+}
+
+// Ratings returns an empty rating state.
+func (c *Client) Ratings() *Ratings {
+	return &Ratings{c: c, tracks: make(map[string]trackRating)}
+}
+
+// Track records the tracks of a playlist of a station, with the rating
+// Pandora reports in SongRating. Tracks already rated through r keep their
+// rating, as it is more recent.
+func (r *Ratings) Track(stationToken string, items []response.PlaylistItem) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, item := range items {
+		if item.IsAd() || item.TrackToken == "" {
+			continue
+		}
+		if _, ok := r.tracks[item.TrackToken]; ok {
+			continue
+		}
+
+		rating := Unrated
+		switch {
+		case item.SongRating > 0:
+			rating = ThumbsUp
+		case item.SongRating < 0:
+			rating = ThumbsDown
+		}
+		r.tracks[item.TrackToken] = trackRating{stationToken: stationToken, rating: rating}
+	}
+}
+
+// Rating returns the rating of a track, Unrated if it is unknown.
+func (r *Ratings) Rating(trackToken string) Rating {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.tracks[trackToken].rating
+}
+
+// Rate gives a track on a station a rating. Unrated removes the rating,
+// which is only possible for ratings given through r.
+func (r *Ratings) Rate(stationToken, trackToken string, rating Rating) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	before := r.tracks[trackToken]
+	if before.rating == rating {
+		return nil
+	}
+
+	cur := before
+	cur.stationToken = stationToken
+	after, err := r.set(trackToken, cur, rating)
+	r.tracks[trackToken] = after
+	if err != nil {
+		return err
+	}
+	r.history = append(r.history, ratingChange{trackToken: trackToken, before: before})
+	return nil
+}
+
+// Undo reverts the last change made by Rate, returning the token of the
+// track concerned.
+func (r *Ratings) Undo() (trackToken string, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(r.history) == 0 {
+		return "", ErrNothingToUndo
+	}
+	last := r.history[len(r.history)-1]
+
+	after, err := r.set(last.trackToken, r.tracks[last.trackToken], last.before.rating)
+	r.tracks[last.trackToken] = after
+	if err != nil {
+		return last.trackToken, err
+	}
+	r.history = r.history[:len(r.history)-1]
+	return last.trackToken, nil
+}
+
+// set changes the rating of a track from cur to rating on Pandora and
+// returns the new state, which is also valid if it fails half way.
+func (r *Ratings) set(trackToken string, cur trackRating, rating Rating) (trackRating, error) {
+	if cur.feedbackID != "" {
+		if err := r.c.StationDeleteFeedback(cur.feedbackID); err != nil {
+			return cur, err
+		}
+		cur.rating, cur.feedbackID = Unrated, ""
+	} else if rating == Unrated {
+		return cur, ErrNoFeedbackID
+	}
+
+	if rating != Unrated {
+		resp, err := r.c.StationAddFeedback(cur.stationToken, trackToken, rating == ThumbsUp)
+		if err != nil {
+			return cur, err
+		}
+		cur.rating, cur.feedbackID = rating, resp.FeedbackID
+	}
+	return cur, nil
+}
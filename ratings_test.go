@@ -0,0 +1,83 @@
+package gopiano
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"denniskupec.com/gopiano/request"
+	"denniskupec.com/gopiano/response"
+)
+
+func TestRatings(t *testing.T) {
+	var calls []string
+	n := 0
+	c, _ := NewClient(AndroidClient)
+	c.Interceptors = []Interceptor{func(req request.Type, data interface{}, next Invoker) error {
+		switch r := req.(type) {
+		case request.AddFeedback:
+			n++
+			id := "f" + strconv.Itoa(n)
+			calls = append(calls, "add "+r.StationToken+" "+r.TrackToken+" "+strconv.FormatBool(r.IsPositive)+" "+id)
+			data.(*response.StationAddFeedback).FeedbackID = id
+		case request.DeleteFeedback:
+			calls = append(calls, "delete "+r.FeedbackID)
+		}
+		return nil
+	}}
+
+	r := c.Ratings()
+	r.Track("s1", []response.PlaylistItem{
+		{TrackToken: "a", SongRating: 1},
+		{TrackToken: "b"},
+	})
+
+	expect := func(step string, track string, rating Rating, expected ...string) {
+		t.Helper()
+		if got := r.Rating(track); got != rating {
+			t.Errorf("%s: expected %s, got %s", step, rating, got)
+		}
+		if strings.Join(calls, "; ") != strings.Join(expected, "; ") {
+			t.Errorf("%s:\nexpected:\n\t%q\ngot:\n\t%q", step, expected, calls)
+		}
+		calls = nil
+	}
+
+	expect("playlist", "a", ThumbsUp)
+
+	if err := r.Rate("s1", "a", Unrated); err != ErrNoFeedbackID {
+		t.Errorf("expected ErrNoFeedbackID, got %v", err)
+	}
+	expect("unrate foreign", "a", ThumbsUp)
+
+	r.Rate("s1", "a", ThumbsUp)
+	expect("same rating", "a", ThumbsUp)
+
+	r.Rate("s1", "b", ThumbsUp)
+	expect("rate", "b", ThumbsUp, "add s1 b true f1")
+
+	r.Rate("s1", "b", ThumbsDown)
+	expect("change", "b", ThumbsDown, "delete f1", "add s1 b false f2")
+
+	r.Rate("s1", "a", ThumbsDown)
+	expect("change foreign", "a", ThumbsDown, "add s1 a false f3")
+
+	// Tracking a playlist again keeps the newer local rating.
+	r.Track("s1", []response.PlaylistItem{{TrackToken: "a", SongRating: 1}})
+	expect("playlist again", "a", ThumbsDown)
+
+	if track, err := r.Undo(); err != nil || track != "a" {
+		t.Errorf("undo: got %q, %v", track, err)
+	}
+	expect("undo foreign", "a", ThumbsUp, "delete f3", "add s1 a true f4")
+
+	r.Undo()
+	expect("undo change", "b", ThumbsUp, "delete f2", "add s1 b true f5")
+
+	r.Undo()
+	expect("undo rate", "b", Unrated, "delete f5")
+
+	if _, err := r.Undo(); err != ErrNothingToUndo {
+		t.Errorf("expected ErrNothingToUndo, got %v", err)
+	}
+}
@@ -0,0 +1,105 @@
+/*
+Package replaygain levels playback volume between tracks using the gain Pandora
+reports for every playlist item (response.Gain).
+*/
+package replaygain
+
+import (
+	"encoding/binary"
+	"io"
+	"math"
+)
+
+// Options adjust how a gain is turned into a linear scale.
+type Options struct {
+	// PreAmp in dB is added to every gain.
+	PreAmp float64
+	// Peak is the track's peak amplitude relative to full scale.
+	// Pandora does not provide it, so 0 is treated as 1.0.
+	Peak float64
+	// PreventClipping limits the scale so that the peak stays below full scale.
+	PreventClipping bool
+}
+
+// Scale converts a gain in dB to a linear factor to multiply samples with.
+func Scale(gain float64, opt Options) float64 {
+	scale := math.Pow(10, (gain+opt.PreAmp)/20)
+
+	if opt.PreventClipping {
+		peak := opt.Peak
+		if peak <= 0 {
+			peak = 1
+		}
+		if scale*peak > 1 {
+			scale = 1 / peak
+		}
+	}
+
+	return scale
+}
+
+// NewReader returns a reader that multiplies the signed 16-bit little endian
+// PCM samples read from r by scale. Samples that would overflow are clamped.
+func NewReader(r io.Reader, scale float64) io.Reader {
+	return &reader{r: r, scale: scale}
+}
+
+type reader struct {
+	r     io.Reader
+	scale float64
+	buf   [4096]byte
+	out   []byte // scaled samples not yet read
+	odd   int    // 1 if carry holds the first byte of an incomplete sample
+	carry byte
+	err   error
+}
+
+func (r *reader) Read(p []byte) (n int, err error) {
+	for len(r.out) == 0 && r.err == nil {
+		r.fill()
+	}
+
+	n = copy(p, r.out)
+	r.out = r.out[n:]
+	if len(r.out) == 0 && r.err != nil {
+		return n, r.err
+	}
+	return n, nil
+}
+
+func (r *reader) fill() {
+	if r.odd == 1 {
+		r.buf[0] = r.carry
+	}
+	n, err := r.r.Read(r.buf[r.odd:])
+	n += r.odd
+	even := n &^ 1
+
+	for i := 0; i < even; i += 2 {
+		s := float64(int16(binary.LittleEndian.Uint16(r.buf[i:]))) * r.scale
+		binary.LittleEndian.PutUint16(r.buf[i:], uint16(clamp(s)))
+	}
+
+	if err != nil {
+		// Pass a dangling byte through untouched.
+		r.out, r.odd, r.err = r.buf[:n], 0, err
+		return
+	}
+
+	// Hold back the incomplete sample until the rest of it arrives.
+	r.out = r.buf[:even]
+	if r.odd = n - even; r.odd == 1 {
+		r.carry = r.buf[even]
+	}
+}
+
+func clamp(s float64) int16 {
+	s = math.Round(s)
+	if s > math.MaxInt16 {
+		return math.MaxInt16
+	}
+	if s < math.MinInt16 {
+		return math.MinInt16
+	}
+	return int16(s)
+}
@@ -0,0 +1,63 @@
+package replaygain
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"math"
+	"testing"
+	"testing/iotest"
+)
+
+func TestScale(t *testing.T) {
+	data := []struct {
+		Gain  float64
+		Opt   Options
+		Scale float64
+	}{
+		{0, Options{}, 1},
+		{-6.0206, Options{}, 0.5},
+		{6.0206, Options{}, 2},
+		{0, Options{PreAmp: 6.0206}, 2},
+		{6.0206, Options{PreventClipping: true}, 1},
+		{6.0206, Options{PreventClipping: true, Peak: 0.8}, 1.25},
+		{-6.0206, Options{PreventClipping: true}, 0.5},
+	}
+
+	for _, d := range data {
+		if s := Scale(d.Gain, d.Opt); math.Abs(s-d.Scale) > 1e-4 {
+			t.Errorf("Scale(%v, %+v): expected %v, got %v", d.Gain, d.Opt, d.Scale, s)
+		}
+	}
+}
+
+func TestReader(t *testing.T) {
+	in := []int16{0, 1000, -1000, 20000, -20000, math.MaxInt16, math.MinInt16}
+	expected := []int16{0, 2000, -2000, math.MaxInt16, math.MinInt16, math.MaxInt16, math.MinInt16}
+
+	var pcm bytes.Buffer
+	binary.Write(&pcm, binary.LittleEndian, in)
+
+	// OneByteReader splits every sample across reads.
+	r := NewReader(iotest.OneByteReader(bytes.NewReader(pcm.Bytes())), 2)
+	out, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := make([]int16, len(out)/2)
+	binary.Read(bytes.NewReader(out), binary.LittleEndian, got)
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Errorf("sample %d: expected %d, got %d", i, expected[i], got[i])
+		}
+	}
+
+	if err := iotest.TestReader(NewReader(bytes.NewReader(pcm.Bytes()), 1), pcm.Bytes()); err != nil {
+		t.Error(err)
+	}
+	if _, err := NewReader(bytes.NewReader(nil), 1).Read(make([]byte, 4)); err != io.EOF {
+		t.Errorf("expected EOF, got %v", err)
+	}
+}
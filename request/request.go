@@ -18,6 +18,10 @@ func (p protocol) URL() string {
 	}
 }
 
+func (p protocol) String() string {
+	return strings.TrimSuffix(p.URL(), "://")
+}
+
 const (
 	HTTP protocol = iota
 	HTTPS
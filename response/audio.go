@@ -0,0 +1,187 @@
+package response
+
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Keys of a playlist item's audioUrlMap.
+const (
+	LowQuality    = "lowQuality"
+	MediumQuality = "mediumQuality"
+	HighQuality   = "highQuality"
+)
+
+// Stream types that Pandora only serves to Pandora One subscribers. The
+// audioUrlMap already matches the account, and free accounts get every
+// other additional stream type, including HTTP_128_MP3.
+var subscriberTypes = map[string]bool{
+	"HTTP_192_MP3": true,
+}
+
+// ErrNoAudio is returned by AudioPreference.Select when no stream is acceptable.
+var ErrNoAudio = errors.New("no audio stream matches preference")
+
+// AudioStream is a single entry of a playlist item's audioUrlMap.
+type AudioStream struct {
+	Bitrate  string `json:"bitrate"`
+	Encoding string `json:"encoding"`
+	AudioURL string `json:"audioUrl"`
+	Protocol string `json:"protocol"`
+
+	Extra map[string]json.RawMessage `json:"-"` // fields unknown to this package
+}
+
+// AdditionalAudioURL holds the URLs returned for the stream types requested with
+// request.GetPlaylist.AdditionalAudioURL, in the order they were requested.
+// Pandora sends a plain string instead of a list if only one type was requested.
+type AdditionalAudioURL []string
+
+func (a *AdditionalAudioURL) UnmarshalJSON(data []byte) error {
+	var url string
+	if err := json.Unmarshal(data, &url); err == nil {
+		*a = AdditionalAudioURL{url}
+		return nil
+	}
+
+	var urls []string
+	if err := json.Unmarshal(data, &urls); err != nil {
+		return err
+	}
+	*a = urls
+	return nil
+}
+
+// Stream describes a playable audio stream of a playlist item.
+type Stream struct {
+	Quality  string // audioUrlMap key or stream type name such as HTTP_128_MP3
+	Bitrate  int    // kbit/s, 0 if unknown
+	Encoding string // lower case, e.g. "aacplus" or "mp3"
+	URL      string
+	Protocol string
+}
+
+// Streams lists all streams of a playlist item, highest quality first.
+// Argument additionalTypes is the value that was sent as request.GetPlaylist.AdditionalAudioURL
+// and is needed to describe the entries of additional.
+func Streams(audio map[string]AudioStream, additionalTypes string, additional AdditionalAudioURL) []Stream {
+	qualities := make([]string, 0, len(audio))
+	for quality := range audio {
+		qualities = append(qualities, quality)
+	}
+	sort.Strings(qualities)
+
+	streams := make([]Stream, 0, len(audio)+len(additional))
+	for _, quality := range qualities {
+		a := audio[quality]
+		bitrate, _ := strconv.Atoi(strings.TrimSpace(a.Bitrate))
+		streams = append(streams, Stream{
+			Quality:  quality,
+			Bitrate:  bitrate,
+			Encoding: strings.ToLower(a.Encoding),
+			URL:      a.AudioURL,
+			Protocol: a.Protocol,
+		})
+	}
+	var types []string
+	if additionalTypes != "" {
+		types = strings.Split(additionalTypes, ",")
+	}
+	for i, url := range additional {
+		if i >= len(types) {
+			break
+		}
+		st := strings.TrimSpace(types[i])
+		s := Stream{Quality: st, URL: url, Protocol: "http"}
+
+		// Stream type names look like HTTP_<bitrate>_<encoding>[_<variant>].
+		if f := strings.Split(st, "_"); len(f) >= 3 {
+			s.Bitrate, _ = strconv.Atoi(f[1])
+			s.Encoding = strings.ToLower(f[2])
+		}
+		streams = append(streams, s)
+	}
+
+	// Equal bitrates keep audioUrlMap entries first, as they match the account.
+	sort.SliceStable(streams, func(i, j int) bool {
+		return streams[i].Bitrate > streams[j].Bitrate
+	})
+	return streams
+}
+
+// AudioPreference decides which stream of a playlist item to play.
+type AudioPreference struct {
+	// MaxBitrate in kbit/s, 0 for no limit.
+	MaxBitrate int
+	// Encodings that may be played, e.g. []string{"mp3"}. Empty allows all.
+	Encodings []string
+	// Subscriber should be set from UserCanSubscribe.IsSubscriber.
+	// Stream types reserved for subscribers, such as HTTP_192_MP3, are
+	// skipped for non-subscribers.
+	Subscriber bool
+	// Fallback is the order in which qualities (audioUrlMap keys or stream
+	// type names) are tried. If empty the highest acceptable bitrate wins.
+	Fallback []string
+}
+
+// DefaultAudioPreference plays the best stream available to a free account.
+var DefaultAudioPreference = AudioPreference{
+	Fallback: []string{HighQuality, MediumQuality, LowQuality},
+}
+
+// Select picks the preferred stream, or returns ErrNoAudio.
+func (p AudioPreference) Select(streams []Stream) (Stream, error) {
+	var ok []Stream
+	for _, s := range streams {
+		if p.accepts(s) {
+			ok = append(ok, s)
+		}
+	}
+
+	if len(p.Fallback) == 0 {
+		best := -1
+		for i, s := range ok {
+			if best < 0 || s.Bitrate > ok[best].Bitrate {
+				best = i
+			}
+		}
+		if best < 0 {
+			return Stream{}, ErrNoAudio
+		}
+		return ok[best], nil
+	}
+
+	for _, quality := range p.Fallback {
+		for _, s := range ok {
+			if strings.EqualFold(s.Quality, quality) {
+				return s, nil
+			}
+		}
+	}
+
+	return Stream{}, ErrNoAudio
+}
+
+func (p AudioPreference) accepts(s Stream) bool {
+	if s.URL == "" {
+		return false
+	}
+	if p.MaxBitrate > 0 && s.Bitrate > p.MaxBitrate {
+		return false
+	}
+	if !p.Subscriber && subscriberTypes[strings.ToUpper(s.Quality)] {
+		return false
+	}
+	if len(p.Encodings) == 0 {
+		return true
+	}
+	for _, enc := range p.Encodings {
+		if strings.EqualFold(enc, s.Encoding) {
+			return true
+		}
+	}
+	return false
+}
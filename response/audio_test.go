@@ -0,0 +1,153 @@
+package response
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+const playlistJSON = `{"items": [{
+	"audioUrlMap": {
+		"highQuality":   {"bitrate": "64", "encoding": "aacplus", "audioUrl": "http://a/high", "protocol": "http"},
+		"mediumQuality": {"bitrate": "64", "encoding": "aacplus", "audioUrl": "http://a/medium", "protocol": "http"},
+		"lowQuality":    {"bitrate": "32", "encoding": "aacplus", "audioUrl": "http://a/low", "protocol": "http"}
+	},
+	"additionalAudioUrl": ["http://a/mp3", "http://a/wma", "http://a/mp3-192"],
+	"trackGain": "-3.31"
+}, {
+	"additionalAudioUrl": "http://b/mp3"
+}]}`
+
+func TestAudioSelect(t *testing.T) {
+	var p StationGetPlaylist
+	if err := json.Unmarshal([]byte(playlistJSON), &p); err != nil {
+		t.Fatal(err)
+	}
+	if g := p.Items[0].TrackGain; g != (NullGain{-3.31, true}) {
+		t.Errorf("expected gain -3.31, got %v", g)
+	}
+	if n := len(p.Items[1].AdditionalAudioURL); n != 1 {
+		t.Fatalf("expected 1 additional url, got %d", n)
+	}
+
+	streams := Streams(p.Items[0].AudioURLMap, "HTTP_128_MP3,HTTP_32_WMA,HTTP_192_MP3", p.Items[0].AdditionalAudioURL)
+
+	var order []string
+	for _, s := range streams {
+		order = append(order, s.Quality)
+	}
+	expected := []string{"HTTP_192_MP3", "HTTP_128_MP3", HighQuality, MediumQuality, LowQuality, "HTTP_32_WMA"}
+	if !reflect.DeepEqual(order, expected) {
+		t.Errorf("\nexpected:\n\t%q\ngot:\n\t%q", expected, order)
+	}
+
+	data := []struct {
+		Pref AudioPreference
+		URL  string
+	}{
+		{DefaultAudioPreference, "http://a/high"},
+		{AudioPreference{}, "http://a/mp3"},
+		{AudioPreference{MaxBitrate: 100}, "http://a/high"},
+		{AudioPreference{MaxBitrate: 48}, "http://a/low"},
+		{AudioPreference{Subscriber: true}, "http://a/mp3-192"},
+		{AudioPreference{Encodings: []string{"MP3"}}, "http://a/mp3"},
+		{AudioPreference{Encodings: []string{"mp3"}, Subscriber: true}, "http://a/mp3-192"},
+		{AudioPreference{Encodings: []string{"aac"}}, ""},
+		{AudioPreference{Encodings: []string{"wma"}}, "http://a/wma"},
+		{AudioPreference{Fallback: []string{"HTTP_128_MP3", MediumQuality}}, "http://a/mp3"},
+		{AudioPreference{Fallback: []string{"HTTP_192_MP3", MediumQuality}}, "http://a/medium"},
+	}
+
+	for _, d := range data {
+		s, err := d.Pref.Select(streams)
+		if d.URL == "" {
+			if err != ErrNoAudio {
+				t.Errorf("%+v: expected ErrNoAudio, got %v", d.Pref, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%+v: %v", d.Pref, err)
+		} else if s.URL != d.URL {
+			t.Errorf("%+v:\nexpected:\n\t%q\ngot:\n\t%q", d.Pref, d.URL, s.URL)
+		}
+	}
+}
+
+func TestGain(t *testing.T) {
+	data := []struct {
+		JSON string
+		Gain NullGain
+	}{
+		{`"-3.31"`, NullGain{-3.31, true}},
+		{`" 1.5 "`, NullGain{1.5, true}},
+		{`2.25`, NullGain{2.25, true}},
+		{`"0.00"`, NullGain{0, true}},
+		{`""`, NullGain{}},
+		{`"n/a"`, NullGain{}},
+		{`null`, NullGain{}},
+		{`{}`, NullGain{}},
+	}
+	for _, d := range data {
+		g := NullGain{7, true}
+		if err := json.Unmarshal([]byte(d.JSON), &g); err != nil {
+			t.Errorf("%s: %v", d.JSON, err)
+		} else if g != d.Gain {
+			t.Errorf("%s: expected %+v, got %+v", d.JSON, d.Gain, g)
+		}
+	}
+
+	var p StationGetPlaylist
+	if err := json.Unmarshal([]byte(`{"items": [{"trackGain": "bad", "songName": "a"}, {"songName": "b"}]}`), &p); err != nil {
+		t.Fatalf("bad gain failed the playlist: %v", err)
+	}
+	for _, item := range p.Items {
+		if item.TrackGain.Valid {
+			t.Errorf("%s: expected unknown gain, got %v", item.SongName, item.TrackGain.Gain)
+		}
+	}
+
+	var g Gain
+	if err := json.Unmarshal([]byte(`"n/a"`), &g); err == nil {
+		t.Error("expected an error for an invalid Gain")
+	}
+
+	for _, g := range []NullGain{{-3.31, true}, {0, true}, {}} {
+		data, err := json.Marshal(g)
+		var back NullGain
+		if err == nil {
+			err = json.Unmarshal(data, &back)
+		}
+		if err != nil || back != g {
+			t.Errorf("%+v did not round trip: %s, %v", g, data, err)
+		}
+	}
+}
+
+func TestPlaylistItem(t *testing.T) {
+	item := PlaylistItem{
+		AudioURLMap: map[string]AudioStream{
+			MediumQuality: {Bitrate: "64", Encoding: "aacplus", AudioURL: "http://a/medium"},
+			LowQuality:    {Bitrate: "32", Encoding: "aacplus", AudioURL: "http://a/low"},
+		},
+		TrackLength: 215,
+	}
+
+	if item.IsAd() {
+		t.Error("track reported as ad")
+	}
+	if d := item.Duration(); d.Seconds() != 215 {
+		t.Errorf("expected 215s, got %v", d)
+	}
+	if s, err := item.BestAudio(); err != nil || s.URL != "http://a/medium" {
+		t.Errorf("unexpected best audio %+v, %v", s, err)
+	}
+
+	ad := PlaylistItem{AdToken: "ad"}
+	if !ad.IsAd() {
+		t.Error("ad not reported as ad")
+	}
+	if _, err := ad.BestAudio(); err != ErrNoAudio {
+		t.Errorf("expected ErrNoAudio, got %v", err)
+	}
+}
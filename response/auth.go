@@ -1,5 +1,7 @@
 package response
 
+import "encoding/json"
+
 type AuthPartnerLogin struct {
 	SyncTime         string `json:"syncTime"`
 	StationSkipLimit int    `json:"stationSkipLimit"`
@@ -15,6 +17,8 @@ type AuthPartnerLogin struct {
 	Urls struct {
 		AutoComplete string `json:"autoComplete"`
 	} `json:"urls"`
+
+	Extra map[string]json.RawMessage `json:"-"` // fields unknown to this package
 }
 
 type AuthUserLogin struct {
@@ -33,4 +37,6 @@ type AuthUserLogin struct {
 	UserProfileURL              string `json:"userProfileUrl"`
 	Username                    string `json:"username"`
 	VideoAdURL                  string `json:"videoAdUrl"`
+
+	Extra map[string]json.RawMessage `json:"-"` // fields unknown to this package
 }
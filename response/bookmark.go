@@ -1,11 +1,15 @@
 package response
 
+import "encoding/json"
+
 type ArtistBookmark struct {
-	ArtURL        string       `json:"artUrl"`
-	ArtistName    string       `json:"artistName"`
-	BookmarkToken string       `json:"bookmarkToken"`
-	DateCreated   DateResponse `json:"dateCreated"`
-	MusicToken    string       `json:"musicToken"`
+	ArtURL        string `json:"artUrl"`
+	ArtistName    string `json:"artistName"`
+	BookmarkToken string `json:"bookmarkToken"`
+	DateCreated   Date   `json:"dateCreated"`
+	MusicToken    string `json:"musicToken"`
+
+	Extra map[string]json.RawMessage `json:"-"` // fields unknown to this package
 }
 
 type BookmarkAddArtistBookmark struct {
@@ -13,15 +17,17 @@ type BookmarkAddArtistBookmark struct {
 }
 
 type SongBookmark struct {
-	AlbumName     string       `json:"artistName"`
-	ArtURL        string       `json:"artUrl"`
-	ArtistName    string       `json:"artistName"`
-	BookmarkToken string       `json:"bookmarkToken"`
-	DateCreated   DateResponse `json:"dateCreated"`
-	MusicToken    string       `json:"musicToken"`
-	SampleGain    string       `json:"sampleGain"`
-	SampleURL     string       `json:"sampleUrl"`
-	SongName      string       `json:"songName"`
+	AlbumName     string   `json:"albumName"`
+	ArtURL        string   `json:"artUrl"`
+	ArtistName    string   `json:"artistName"`
+	BookmarkToken string   `json:"bookmarkToken"`
+	DateCreated   Date     `json:"dateCreated"`
+	MusicToken    string   `json:"musicToken"`
+	SampleGain    NullGain `json:"sampleGain"`
+	SampleURL     string   `json:"sampleUrl"`
+	SongName      string   `json:"songName"`
+
+	Extra map[string]json.RawMessage `json:"-"` // fields unknown to this package
 }
 
 type BookmarkAddSongBookmark struct {
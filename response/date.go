@@ -0,0 +1,43 @@
+package response
+
+import (
+	"bytes"
+	"encoding/json"
+	"time"
+)
+
+// Date is a point in time sent by Pandora as a serialized Java Date, such
+// as dateCreated. It is read from the object's "time" field, the epoch in
+// milliseconds, and written back as an object with just that field.
+type Date struct {
+	time.Time
+}
+
+func (d *Date) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		d.Time = time.Time{}
+		return nil
+	}
+
+	var v struct {
+		Time *int64 `json:"time"`
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	if v.Time == nil {
+		d.Time = time.Time{}
+		return nil
+	}
+	d.Time = time.Unix(0, *v.Time*int64(time.Millisecond))
+	return nil
+}
+
+func (d Date) MarshalJSON() ([]byte, error) {
+	if d.IsZero() {
+		return []byte("null"), nil
+	}
+	return json.Marshal(struct {
+		Time int64 `json:"time"`
+	}{d.UnixNano() / int64(time.Millisecond)})
+}
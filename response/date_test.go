@@ -0,0 +1,52 @@
+package response
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+// A Java Date as Pandora sends it: 2011-12-31 22:00 in UTC-8.
+const javaDate = `{"nanos": 0, "seconds": 0, "year": 111, "month": 11, "hours": 22,
+	"time": 1325397600000, "date": 31, "minutes": 0, "day": 6, "timezoneOffset": 480}`
+
+func TestDate(t *testing.T) {
+	expected := time.Date(2012, time.January, 1, 6, 0, 0, 0, time.UTC)
+
+	var f FeedbackResponse
+	if err := json.Unmarshal([]byte(`{"dateCreated": `+javaDate+`}`), &f); err != nil {
+		t.Fatal(err)
+	}
+	if !f.DateCreated.Equal(expected) {
+		t.Errorf("expected %v, got %v", expected, f.DateCreated)
+	}
+
+	var old DateResponse
+	if err := json.Unmarshal([]byte(javaDate), &old); err != nil {
+		t.Fatal(err)
+	}
+	if d := old.GetDate(); !d.Equal(expected) {
+		t.Errorf("GetDate: expected %v, got %v", expected, d)
+	}
+	if err := json.Unmarshal([]byte(`{"nanos": 5}`), &old); err != nil || old.Nanos != 5 {
+		t.Errorf("nanos: expected 5, got %d (%v)", old.Nanos, err)
+	}
+
+	data, err := json.Marshal(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var f2 FeedbackResponse
+	if err := json.Unmarshal(data, &f2); err != nil {
+		t.Fatal(err)
+	}
+	if !f2.DateCreated.Equal(expected) {
+		t.Errorf("round trip: expected %v, got %v from %s", expected, f2.DateCreated, data)
+	}
+
+	var empty FeedbackResponse
+	data, _ = json.Marshal(empty)
+	if err := json.Unmarshal(data, &f2); err != nil || !f2.DateCreated.IsZero() {
+		t.Errorf("zero date did not round trip: %s, %v", data, err)
+	}
+}
@@ -0,0 +1,153 @@
+package response
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// SchemaDrift describes how the result of a call differs from the
+// response type it was unmarshaled into.
+type SchemaDrift struct {
+	Method  string
+	Unknown []string // fields Pandora sent that the type does not have
+	Missing []string // fields of the type Pandora did not send
+}
+
+func (d SchemaDrift) Error() string {
+	var parts []string
+	if len(d.Unknown) > 0 {
+		parts = append(parts, "unknown fields "+strings.Join(d.Unknown, ", "))
+	}
+	if len(d.Missing) > 0 {
+		parts = append(parts, "missing fields "+strings.Join(d.Missing, ", "))
+	}
+	return fmt.Sprintf("%s: %s", d.Method, strings.Join(parts, "; "))
+}
+
+// CheckSchema compares a call result with the type of v, which it is to be
+// unmarshaled into, and returns the differences, or nil if there are none.
+//
+// Fields are named by their path, such as "items[].songName". A field of a
+// list element is only missing if no element has it, and fields tagged
+// omitempty are never missing.
+func CheckSchema(method string, data []byte, v interface{}) *SchemaDrift {
+	var doc interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil
+	}
+
+	c := checker{seen: make(map[string]map[string]bool), types: make(map[string]reflect.Type)}
+	c.walk("", doc, reflect.TypeOf(v))
+
+	d := &SchemaDrift{Method: method}
+	for path, t := range c.types {
+		fields := jsonFields(t)
+		for name := range c.seen[path] {
+			if _, ok := fields.lookup(name); !ok {
+				d.Unknown = append(d.Unknown, join(path, name))
+			}
+		}
+		for _, f := range fields.list {
+			if !f.omitEmpty && !c.seenFold(path, f.name) {
+				d.Missing = append(d.Missing, join(path, f.name))
+			}
+		}
+	}
+	if len(d.Unknown) == 0 && len(d.Missing) == 0 {
+		return nil
+	}
+	sort.Strings(d.Unknown)
+	sort.Strings(d.Missing)
+	return d
+}
+
+func join(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+type checker struct {
+	seen  map[string]map[string]bool // keys found in the objects at a path
+	types map[string]reflect.Type    // struct type expected at a path
+}
+
+var (
+	unmarshalerType     = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	stationResponseType = reflect.TypeOf(StationResponse{})
+	stationType         = reflect.TypeOf(Station{})
+)
+
+func (c *checker) walk(path string, doc interface{}, t reflect.Type) {
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || doc == nil {
+		return
+	}
+	if t == stationResponseType {
+		// Holds a station that is the whole result.
+		t = stationType
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		obj, ok := doc.(map[string]interface{})
+		if !ok {
+			return
+		}
+		// Types that decode themselves, such as Date, are leaves unless
+		// they merely collect extra fields.
+		if reflect.PtrTo(t).Implements(unmarshalerType) {
+			if _, ok := t.FieldByName("Extra"); !ok {
+				return
+			}
+		}
+
+		if c.seen[path] == nil {
+			c.seen[path] = make(map[string]bool)
+		}
+		c.types[path] = t
+		fields := jsonFields(t)
+		for name, value := range obj {
+			c.seen[path][name] = true
+			if f, ok := fields.lookup(name); ok {
+				c.walk(join(path, f.name), value, f.typ)
+			}
+		}
+
+	case reflect.Slice, reflect.Array:
+		list, ok := doc.([]interface{})
+		if !ok {
+			return
+		}
+		for _, value := range list {
+			c.walk(path+"[]", value, t.Elem())
+		}
+
+	case reflect.Map:
+		obj, ok := doc.(map[string]interface{})
+		if !ok {
+			return
+		}
+		for _, value := range obj {
+			c.walk(path+".*", value, t.Elem())
+		}
+	}
+}
+
+func (c *checker) seenFold(path, name string) bool {
+	if c.seen[path][name] {
+		return true
+	}
+	for k := range c.seen[path] {
+		if strings.EqualFold(k, name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,74 @@
+package response
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+const driftJSON = `{"items": [{
+	"trackToken": "t1",
+	"songName": "Song",
+	"artistName": "Artist",
+	"trackLength": 200,
+	"dateRecorded": {"time": 1},
+	"audioUrlMap": {"highQuality": {"bitrate": "64", "encoding": "aacplus", "audioUrl": "u", "protocol": "http", "codec": "he"}}
+}, {
+	"adToken": "ad"
+}], "newTopLevel": true}`
+
+func TestExtra(t *testing.T) {
+	var p StationGetPlaylist
+	if err := json.Unmarshal([]byte(driftJSON), &p); err != nil {
+		t.Fatal(err)
+	}
+
+	if v := string(p.Extra["newTopLevel"]); v != "true" {
+		t.Errorf("expected newTopLevel in Extra, got %q", v)
+	}
+	if v := string(p.Items[0].Extra["dateRecorded"]); v != `{"time": 1}` {
+		t.Errorf("expected dateRecorded in Extra, got %q", v)
+	}
+	if v := string(p.Items[0].AudioURLMap[HighQuality].Extra["codec"]); v != `"he"` {
+		t.Errorf("expected codec in Extra, got %q", v)
+	}
+	if p.Items[1].Extra != nil {
+		t.Errorf("unexpected Extra %v", p.Items[1].Extra)
+	}
+	if p.Items[0].SongName != "Song" || p.Items[1].AdToken != "ad" {
+		t.Errorf("known fields lost: %+v", p.Items)
+	}
+}
+
+func TestCheckSchema(t *testing.T) {
+	var p StationGetPlaylist
+	d := CheckSchema("station.getPlaylist", []byte(driftJSON), &p)
+	if d == nil {
+		t.Fatal("expected drift")
+	}
+
+	unknown := []string{"items[].audioUrlMap.*.codec", "items[].dateRecorded", "newTopLevel"}
+	if !reflect.DeepEqual(d.Unknown, unknown) {
+		t.Errorf("\nexpected:\n\t%q\ngot:\n\t%q", unknown, d.Unknown)
+	}
+
+	// Fields present in any item are not missing.
+	for _, f := range d.Missing {
+		switch f {
+		case "items[].songName", "items[].adToken", "items[].trackLength":
+			t.Errorf("%s reported missing", f)
+		}
+	}
+	found := false
+	for _, f := range d.Missing {
+		found = found || f == "items[].albumName"
+	}
+	if !found {
+		t.Errorf("items[].albumName not reported missing: %q", d.Missing)
+	}
+
+	var s StationGetGenreStationsChecksum
+	if d := CheckSchema("station.getGenreStationsChecksum", []byte(`{"checksum": "abc"}`), &s); d != nil {
+		t.Errorf("unexpected drift %v", d)
+	}
+}
@@ -4,6 +4,9 @@ import (
 	"fmt"
 )
 
+// InvalidAuthToken is the error code Pandora returns once a session expired.
+const InvalidAuthToken = 1001
+
 var ErrorCodeMap map[int]string = map[int]string{
 	0:    "INTERNAL",
 	1:    "MAINTENCANCE_MODE",
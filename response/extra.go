@@ -0,0 +1,247 @@
+package response
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// Response types keep the fields of Pandora's responses that they do not
+// know in their Extra map, so new fields can be read before this package
+// learns about them. Each type unmarshals through an alias, which has the
+// same fields but not the UnmarshalJSON method, and then collects the rest.
+
+func unmarshalExtra(data []byte, v interface{}, extra *map[string]json.RawMessage) error {
+	*extra = nil
+	if err := json.Unmarshal(data, v); err != nil {
+		return err
+	}
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		// Not an object, e.g. null.
+		return nil
+	}
+
+	fields := jsonFields(reflect.TypeOf(v).Elem())
+	for name, value := range raw {
+		if _, ok := fields.lookup(name); ok {
+			continue
+		}
+		if *extra == nil {
+			*extra = make(map[string]json.RawMessage)
+		}
+		(*extra)[name] = value
+	}
+	return nil
+}
+
+// field is a struct field as seen by encoding/json.
+type field struct {
+	name      string
+	typ       reflect.Type
+	omitEmpty bool
+}
+
+type fieldSet struct {
+	list  []field
+	exact map[string]int
+	fold  map[string]int
+}
+
+// lookup finds a field by JSON name, ignoring case like encoding/json.
+func (fs *fieldSet) lookup(name string) (field, bool) {
+	i, ok := fs.exact[name]
+	if !ok {
+		i, ok = fs.fold[strings.ToLower(name)]
+	}
+	if !ok {
+		return field{}, false
+	}
+	return fs.list[i], true
+}
+
+var fieldCache sync.Map // reflect.Type -> *fieldSet
+
+// jsonFields lists the fields encoding/json decodes into a struct type,
+// including those of embedded structs.
+func jsonFields(t reflect.Type) *fieldSet {
+	if fs, ok := fieldCache.Load(t); ok {
+		return fs.(*fieldSet)
+	}
+
+	fs := &fieldSet{exact: make(map[string]int), fold: make(map[string]int)}
+	var add func(t reflect.Type)
+	add = func(t reflect.Type) {
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			tag := f.Tag.Get("json")
+			if tag == "-" {
+				continue
+			}
+			name, opts, _ := strings.Cut(tag, ",")
+			if f.Anonymous && name == "" && f.Type.Kind() == reflect.Struct {
+				add(f.Type)
+				continue
+			}
+			if f.PkgPath != "" {
+				continue
+			}
+			if name == "" {
+				name = f.Name
+			}
+			if _, dup := fs.exact[name]; dup {
+				continue
+			}
+			fs.exact[name] = len(fs.list)
+			if _, dup := fs.fold[strings.ToLower(name)]; !dup {
+				fs.fold[strings.ToLower(name)] = len(fs.list)
+			}
+			fs.list = append(fs.list, field{name: name, typ: f.Type, omitEmpty: opts == "omitempty"})
+		}
+	}
+	add(t)
+
+	fieldCache.Store(t, fs)
+	return fs
+}
+
+func (a *AudioStream) UnmarshalJSON(data []byte) error {
+	type plain AudioStream
+	return unmarshalExtra(data, (*plain)(a), &a.Extra)
+}
+
+func (a *AuthPartnerLogin) UnmarshalJSON(data []byte) error {
+	type plain AuthPartnerLogin
+	return unmarshalExtra(data, (*plain)(a), &a.Extra)
+}
+
+func (a *AuthUserLogin) UnmarshalJSON(data []byte) error {
+	type plain AuthUserLogin
+	return unmarshalExtra(data, (*plain)(a), &a.Extra)
+}
+
+func (u *UserCreateUser) UnmarshalJSON(data []byte) error {
+	return (*AuthUserLogin)(u).UnmarshalJSON(data)
+}
+
+func (b *ArtistBookmark) UnmarshalJSON(data []byte) error {
+	type plain ArtistBookmark
+	return unmarshalExtra(data, (*plain)(b), &b.Extra)
+}
+
+func (b *SongBookmark) UnmarshalJSON(data []byte) error {
+	type plain SongBookmark
+	return unmarshalExtra(data, (*plain)(b), &b.Extra)
+}
+
+func (m *MusicSearch) UnmarshalJSON(data []byte) error {
+	type plain MusicSearch
+	return unmarshalExtra(data, (*plain)(m), &m.Extra)
+}
+
+func (s *SearchSong) UnmarshalJSON(data []byte) error {
+	type plain SearchSong
+	return unmarshalExtra(data, (*plain)(s), &s.Extra)
+}
+
+func (a *SearchArtist) UnmarshalJSON(data []byte) error {
+	type plain SearchArtist
+	return unmarshalExtra(data, (*plain)(a), &a.Extra)
+}
+
+func (f *FeedbackResponse) UnmarshalJSON(data []byte) error {
+	type plain FeedbackResponse
+	return unmarshalExtra(data, (*plain)(f), &f.Extra)
+}
+
+func (e *ExplainTrack) UnmarshalJSON(data []byte) error {
+	type plain ExplainTrack
+	return unmarshalExtra(data, (*plain)(e), &e.Extra)
+}
+
+func (t *Trait) UnmarshalJSON(data []byte) error {
+	type plain Trait
+	return unmarshalExtra(data, (*plain)(t), &t.Extra)
+}
+
+func (s *Station) UnmarshalJSON(data []byte) error {
+	type plain Station
+	return unmarshalExtra(data, (*plain)(s), &s.Extra)
+}
+
+func (m *StationMusic) UnmarshalJSON(data []byte) error {
+	type plain StationMusic
+	return unmarshalExtra(data, (*plain)(m), &m.Extra)
+}
+
+func (s *SongSeed) UnmarshalJSON(data []byte) error {
+	type plain SongSeed
+	return unmarshalExtra(data, (*plain)(s), &s.Extra)
+}
+
+func (a *ArtistSeed) UnmarshalJSON(data []byte) error {
+	type plain ArtistSeed
+	return unmarshalExtra(data, (*plain)(a), &a.Extra)
+}
+
+func (f *StationFeedback) UnmarshalJSON(data []byte) error {
+	type plain StationFeedback
+	return unmarshalExtra(data, (*plain)(f), &f.Extra)
+}
+
+func (s *StationAddMusic) UnmarshalJSON(data []byte) error {
+	type plain StationAddMusic
+	return unmarshalExtra(data, (*plain)(s), &s.Extra)
+}
+
+func (s *StationGetGenreStations) UnmarshalJSON(data []byte) error {
+	type plain StationGetGenreStations
+	return unmarshalExtra(data, (*plain)(s), &s.Extra)
+}
+
+func (g *GenreCategory) UnmarshalJSON(data []byte) error {
+	type plain GenreCategory
+	return unmarshalExtra(data, (*plain)(g), &g.Extra)
+}
+
+func (g *GenreStation) UnmarshalJSON(data []byte) error {
+	type plain GenreStation
+	return unmarshalExtra(data, (*plain)(g), &g.Extra)
+}
+
+func (s *StationGetGenreStationsChecksum) UnmarshalJSON(data []byte) error {
+	type plain StationGetGenreStationsChecksum
+	return unmarshalExtra(data, (*plain)(s), &s.Extra)
+}
+
+func (s *StationGetPlaylist) UnmarshalJSON(data []byte) error {
+	type plain StationGetPlaylist
+	return unmarshalExtra(data, (*plain)(s), &s.Extra)
+}
+
+func (i *PlaylistItem) UnmarshalJSON(data []byte) error {
+	type plain PlaylistItem
+	return unmarshalExtra(data, (*plain)(i), &i.Extra)
+}
+
+func (u *UserCanSubscribe) UnmarshalJSON(data []byte) error {
+	type plain UserCanSubscribe
+	return unmarshalExtra(data, (*plain)(u), &u.Extra)
+}
+
+func (u *UserGetBookmarks) UnmarshalJSON(data []byte) error {
+	type plain UserGetBookmarks
+	return unmarshalExtra(data, (*plain)(u), &u.Extra)
+}
+
+func (u *UserGetStationList) UnmarshalJSON(data []byte) error {
+	type plain UserGetStationList
+	return unmarshalExtra(data, (*plain)(u), &u.Extra)
+}
+
+func (u *UserGetStationListChecksum) UnmarshalJSON(data []byte) error {
+	type plain UserGetStationListChecksum
+	return unmarshalExtra(data, (*plain)(u), &u.Extra)
+}
@@ -0,0 +1,76 @@
+package response
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Gain is a ReplayGain adjustment in dB.
+// Pandora sends it as a string, e.g. "-3.31" in a playlist item's trackGain.
+type Gain float64
+
+func (g *Gain) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		// Accept a bare number as well.
+		var f float64
+		if err := json.Unmarshal(data, &f); err != nil {
+			return err
+		}
+		*g = Gain(f)
+		return nil
+	}
+
+	s = strings.TrimSpace(s)
+	if s == "" {
+		*g = 0
+		return nil
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return fmt.Errorf("invalid gain %q: %v", s, err)
+	}
+	*g = Gain(f)
+	return nil
+}
+
+func (g Gain) MarshalJSON() ([]byte, error) {
+	return json.Marshal(strconv.FormatFloat(float64(g), 'f', -1, 64))
+}
+
+func (g Gain) String() string {
+	return fmt.Sprintf("%.2f dB", float64(g))
+}
+
+// NullGain is a Gain that may be unknown, like a playlist item's trackGain.
+// Pandora leaves it out or sends an empty string if it has none, and a
+// value that cannot be parsed is taken as unknown too, so one bad gain
+// does not fail the whole playlist.
+type NullGain struct {
+	Gain  Gain
+	Valid bool // false if the gain is unknown
+}
+
+func (g *NullGain) UnmarshalJSON(data []byte) error {
+	*g = NullGain{}
+	var s string
+	if bytes.Equal(data, []byte("null")) || json.Unmarshal(data, &s) == nil && strings.TrimSpace(s) == "" {
+		return nil
+	}
+	if err := g.Gain.UnmarshalJSON(data); err != nil {
+		g.Gain = 0
+		return nil
+	}
+	g.Valid = true
+	return nil
+}
+
+func (g NullGain) MarshalJSON() ([]byte, error) {
+	if !g.Valid {
+		return []byte("null"), nil
+	}
+	return g.Gain.MarshalJSON()
+}
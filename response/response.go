@@ -12,11 +12,13 @@ import (
 // called dateCreated.
 // Most of the data is rubish without a little processing but you can use GetDate()
 // and also Time is just a nice UNIX epoch.
+//
+// Deprecated: response types use Date, which holds a time.Time.
 type DateResponse struct {
-	Nanos          int `json:"nano"`
+	Nanos          int `json:"nanos"`
 	Seconds        int `json:"seconds"`
 	Year           int `json:"year"`
-	Month          int `json:"month"`
+	Month          int `json:"month"` // 0 is January
 	Hours          int `json:"hours"`
 	Time           int `json:"time"`
 	Date           int `json:"date"`
@@ -26,41 +28,64 @@ type DateResponse struct {
 }
 
 // Get this mess of ints as a time.Time object. Much nicer.
+//
+// Deprecated: use Date.
 func (d DateResponse) GetDate() time.Time {
-	return time.Date(1900+d.Year, time.Month(d.Month), d.Date, d.Hours, d.Minutes, d.Seconds,
-		d.Nanos, time.FixedZone("Local Time", d.TimezoneOffset*60))
+	return time.Date(1900+d.Year, time.Month(d.Month+1), d.Date, d.Hours, d.Minutes, d.Seconds,
+		d.Nanos, time.FixedZone("Local Time", -d.TimezoneOffset*60))
 }
 
 type MusicSearch struct {
-	NearMatchesAvailable bool   `json:"nearMatchesAvailable"`
-	Explanation          string `json:"explanation"`
-	Songs                []struct {
-		ArtistName string `json:"artistName"`
-		MusicToken string `json:"musicToken"`
-		SongName   string `json:"songName"`
-		Score      int    `json:"score"`
-	} `json:"songs"`
-	Artists []struct {
-		ArtistName  string `json:"artistName"`
-		MusicToken  string `json:"musicToken"`
-		LikelyMatch bool   `json:"likelyMatch"`
-		Score       int    `json:"score"`
-	} `json:"artists"`
+	NearMatchesAvailable bool           `json:"nearMatchesAvailable"`
+	Explanation          string         `json:"explanation"`
+	Songs                []SearchSong   `json:"songs"`
+	Artists              []SearchArtist `json:"artists"`
+
+	Extra map[string]json.RawMessage `json:"-"` // fields unknown to this package
+}
+
+// SearchSong is a song found by MusicSearch.
+type SearchSong struct {
+	ArtistName string `json:"artistName"`
+	MusicToken string `json:"musicToken"`
+	SongName   string `json:"songName"`
+	Score      int    `json:"score"`
+
+	Extra map[string]json.RawMessage `json:"-"` // fields unknown to this package
+}
+
+// SearchArtist is an artist found by MusicSearch.
+type SearchArtist struct {
+	ArtistName  string `json:"artistName"`
+	MusicToken  string `json:"musicToken"`
+	LikelyMatch bool   `json:"likelyMatch"`
+	Score       int    `json:"score"`
+
+	Extra map[string]json.RawMessage `json:"-"` // fields unknown to this package
 }
 
 type FeedbackResponse struct {
-	ArtistName  string       `json:"artistName"`
-	SongName    string       `json:"songName"`
-	DateCreated DateResponse `json:"dateCreated"`
-	FeedbackID  string       `json:"feedbackId"`
-	IsPositive  bool         `json:"isPositive"`
+	ArtistName  string `json:"artistName"`
+	SongName    string `json:"songName"`
+	DateCreated Date   `json:"dateCreated"`
+	FeedbackID  string `json:"feedbackId"`
+	IsPositive  bool   `json:"isPositive"`
+
+	Extra map[string]json.RawMessage `json:"-"` // fields unknown to this package
 }
 
 type ExplainTrack struct {
-	Explanations []struct {
-		FocustTraitName string `json:"focusTraitName"`
-		FocusTraitID    string `json:"focustTraitId"`
-	} `json:"explanations"`
+	Explanations []Trait `json:"explanations"`
+
+	Extra map[string]json.RawMessage `json:"-"` // fields unknown to this package
+}
+
+// Trait is a Music Genome Project attribute of a track.
+type Trait struct {
+	FocusTraitName string `json:"focusTraitName"`
+	FocusTraitID   string `json:"focusTraitId"`
+
+	Extra map[string]json.RawMessage `json:"-"` // fields unknown to this package
 }
 
 type Wrapper struct {
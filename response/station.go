@@ -1,39 +1,68 @@
 package response
 
+import (
+	"encoding/json"
+	"time"
+)
+
 type Station struct {
-	SuppressVideoAds bool         `json:"suppressVideoAds"`
-	StationID        string       `json:"stationId"`
-	AllowAddMusic    bool         `json:"allowAddMusic"`
-	DateCreated      DateResponse `json:"dateCreated"`
-	StationDetailURL string       `json:"stationDetailUrl"`
-	ArtURL           string       `json:"artUrl"`
-	RequiresCleanAds bool         `json:"requiresCleanAds"`
-	StationToken     string       `json:"stationToken"`
-	StationName      string       `json:"stationName"`
-	Music            struct {
-		Songs []struct {
-			SeedID      string       `json:"seedId"`
-			ArtistName  string       `json:"artistName"`
-			SongName    string       `json:"songName"`
-			DateCreated DateResponse `json:"dateCreated"`
-		} `json:"songs"`
-		Artists []struct {
-			SeedID      string       `json:"seedId"`
-			ArtistName  string       `json:"artistName"`
-			DateCreated DateResponse `json:"dateCreated"`
-		} `json:"artists"`
-	} `json:"music"`
-	IsShared           bool     `json:"isShared"`
-	AllowDelete        bool     `json:"allowDelete"`
-	Genre              []string `json:"genre"`
-	IsQuickMix         bool     `json:"isQuickMix"`
-	AllowRename        bool     `json:"allowRename"`
-	StationSharingURL  string   `json:"stationSharingUrl"`
-	QuickMixStationIDs []string `json:"quickMixStationIds"`
-	Feedback           struct {
-		ThumbsDown []FeedbackResponse `json:"thumbsDown"`
-		ThumbsUp   []FeedbackResponse `json:"thumbsUp"`
-	} `json:"feedback"`
+	SuppressVideoAds   bool            `json:"suppressVideoAds"`
+	StationID          string          `json:"stationId"`
+	AllowAddMusic      bool            `json:"allowAddMusic"`
+	DateCreated        Date            `json:"dateCreated"`
+	StationDetailURL   string          `json:"stationDetailUrl"`
+	ArtURL             string          `json:"artUrl"`
+	RequiresCleanAds   bool            `json:"requiresCleanAds"`
+	StationToken       string          `json:"stationToken"`
+	StationName        string          `json:"stationName"`
+	Music              StationMusic    `json:"music"`
+	IsShared           bool            `json:"isShared"`
+	AllowDelete        bool            `json:"allowDelete"`
+	Genre              []string        `json:"genre"`
+	IsQuickMix         bool            `json:"isQuickMix"`
+	AllowRename        bool            `json:"allowRename"`
+	StationSharingURL  string          `json:"stationSharingUrl"`
+	QuickMixStationIDs []string        `json:"quickMixStationIds"`
+	Feedback           StationFeedback `json:"feedback"`
+
+	Extra map[string]json.RawMessage `json:"-"` // fields unknown to this package
+}
+
+// StationMusic lists the seeds of a station.
+type StationMusic struct {
+	Songs   []SongSeed   `json:"songs"`
+	Artists []ArtistSeed `json:"artists"`
+
+	Extra map[string]json.RawMessage `json:"-"` // fields unknown to this package
+}
+
+// SongSeed is a song a station is based on.
+type SongSeed struct {
+	SeedID      string `json:"seedId"`
+	MusicToken  string `json:"musicToken"`
+	ArtistName  string `json:"artistName"`
+	SongName    string `json:"songName"`
+	DateCreated Date   `json:"dateCreated"`
+
+	Extra map[string]json.RawMessage `json:"-"` // fields unknown to this package
+}
+
+// ArtistSeed is an artist a station is based on.
+type ArtistSeed struct {
+	SeedID      string `json:"seedId"`
+	MusicToken  string `json:"musicToken"`
+	ArtistName  string `json:"artistName"`
+	DateCreated Date   `json:"dateCreated"`
+
+	Extra map[string]json.RawMessage `json:"-"` // fields unknown to this package
+}
+
+// StationFeedback lists the songs rated on a station.
+type StationFeedback struct {
+	ThumbsDown []FeedbackResponse `json:"thumbsDown"`
+	ThumbsUp   []FeedbackResponse `json:"thumbsUp"`
+
+	Extra map[string]json.RawMessage `json:"-"` // fields unknown to this package
 }
 
 type StationList []Station
@@ -51,68 +80,116 @@ func (s StationList) Less(i, j int) bool {
 	return s[i].StationName < s[j].StationName
 }
 
-type StationAddFeedback struct {
-	Result FeedbackResponse `json:"result"`
-}
+// StationAddFeedback is the feedback created, which Pandora returns as the
+// whole result of the call.
+type StationAddFeedback = FeedbackResponse
 
 type StationAddMusic struct {
-	ArtistName  string       `json:"artistName"`
-	DateCreated DateResponse `json:"dateCreated"`
-	SeedID      string       `json:"seedId"`
+	ArtistName  string `json:"artistName"`
+	DateCreated Date   `json:"dateCreated"`
+	SeedID      string `json:"seedId"`
+
+	Extra map[string]json.RawMessage `json:"-"` // fields unknown to this package
 }
 
+// StationResponse holds a station that Pandora returns as the whole result of a call.
 type StationResponse struct {
 	Result Station `json:"result"`
 }
-type StationCreateStation StationResponse
-type StationGetStation StationResponse
-type StationRenameStation StationResponse
-type StationTransformSharedStation StationResponse
+
+func (s *StationResponse) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, &s.Result)
+}
+
+type StationCreateStation = StationResponse
+type StationGetStation = StationResponse
+type StationRenameStation = StationResponse
+type StationTransformSharedStation = StationResponse
 
 type StationGetGenreStations struct {
-	Categories []struct {
-		CategoryName string `json:"categoryName"`
-		Stations     []struct {
-			StationToken string `json:"stationToken"`
-			StationName  string `json:"stationName"`
-			StationID    string `json:"stationId"`
-		}
-	} `json:"categories"`
+	Categories []GenreCategory `json:"categories"`
+
+	Extra map[string]json.RawMessage `json:"-"` // fields unknown to this package
+}
+
+// GenreCategory is a group of genre stations, such as "Rock".
+type GenreCategory struct {
+	CategoryName string         `json:"categoryName"`
+	Stations     []GenreStation `json:"stations"`
+
+	Extra map[string]json.RawMessage `json:"-"` // fields unknown to this package
+}
+
+// GenreStation is a predefined station. A user station is created from it
+// by passing its StationToken as track token with music type "song".
+type GenreStation struct {
+	StationToken string `json:"stationToken"`
+	StationName  string `json:"stationName"`
+	StationID    string `json:"stationId"`
+
+	Extra map[string]json.RawMessage `json:"-"` // fields unknown to this package
 }
 
 type StationGetGenreStationsChecksum struct {
 	Checksum string `json:"checksum"`
+
+	Extra map[string]json.RawMessage `json:"-"` // fields unknown to this package
 }
 
 type StationGetPlaylist struct {
-	Items []struct {
-		TrackToken      string `json:"trackToken"`
-		ArtistName      string `json:"artistName"`
-		AlbumName       string `json:"albumName"`
-		AmazonAlbumURL  string `json:"amazonAlbumUrl"`
-		SongExplorerURL string `json:"songExplorerUrl"`
-		AlbumArtURL     string `json:"albumArtUrl"`
-		ArtistDetailURL string `json:"artistDetailUrl"`
-		AudioURLMap     map[string]struct {
-			Bitrate  string `json:"bitrate"`
-			Encoding string `json:"encoding"`
-			AudioURL string `json:"audioUrl"`
-			Protocol string `json:"protocol"`
-		} `json:"audioUrlMap"`
-		ITunesSongURL          string `json:"itunesSongUrl"`
-		AmazonAlbumAsin        string `json:"amazonAlbumAsin"`
-		AmazonAlbumDigitalAsin string `json:"amazonAlbumDigitalAsin"`
-		ArtistExplorerURL      string `json:"artistExplorerUrl"`
-		SongName               string `json:"songName"`
-		AlbumDetailURL         string `json:"albumDetailUrl"`
-		SongDetailURL          string `json:"songDetailUrl"`
-		StationID              string `json:"stationId"`
-		SongRating             int    `json:"songRating"`
-		TrackGain              string `json:"trackGain"`
-		AlbumExplorerURL       string `json:"albumExplorerUrl"`
-		AllowFeedback          bool   `json:"allowFeedback"`
-		AmazonSongDigitalAsin  string `json:"amazonSongDigitalAsin"`
-		NowPlayingStationAdURL string `json:"nowPlayingStationAdUrl"`
-		AdToken                string `json:"adToken"`
-	} `json:"items"`
+	Items []PlaylistItem `json:"items"`
+
+	Extra map[string]json.RawMessage `json:"-"` // fields unknown to this package
+}
+
+// PlaylistItem is a track, or an ad, of a playlist.
+type PlaylistItem struct {
+	TrackToken             string                 `json:"trackToken"`
+	ArtistName             string                 `json:"artistName"`
+	AlbumName              string                 `json:"albumName"`
+	AmazonAlbumURL         string                 `json:"amazonAlbumUrl"`
+	SongExplorerURL        string                 `json:"songExplorerUrl"`
+	AlbumArtURL            string                 `json:"albumArtUrl"`
+	ArtistDetailURL        string                 `json:"artistDetailUrl"`
+	AudioURLMap            map[string]AudioStream `json:"audioUrlMap"`
+	ITunesSongURL          string                 `json:"itunesSongUrl"`
+	AmazonAlbumAsin        string                 `json:"amazonAlbumAsin"`
+	AmazonAlbumDigitalAsin string                 `json:"amazonAlbumDigitalAsin"`
+	ArtistExplorerURL      string                 `json:"artistExplorerUrl"`
+	SongName               string                 `json:"songName"`
+	AlbumDetailURL         string                 `json:"albumDetailUrl"`
+	SongDetailURL          string                 `json:"songDetailUrl"`
+	StationID              string                 `json:"stationId"`
+	SongRating             int                    `json:"songRating"`
+	TrackGain              NullGain               `json:"trackGain"`
+	AlbumExplorerURL       string                 `json:"albumExplorerUrl"`
+	AllowFeedback          bool                   `json:"allowFeedback"`
+	AmazonSongDigitalAsin  string                 `json:"amazonSongDigitalAsin"`
+	NowPlayingStationAdURL string                 `json:"nowPlayingStationAdUrl"`
+	AdToken                string                 `json:"adToken"`
+	TrackLength            int                    `json:"trackLength"` // seconds
+
+	AdditionalAudioURL AdditionalAudioURL `json:"additionalAudioUrl"`
+
+	Extra map[string]json.RawMessage `json:"-"` // fields unknown to this package
+}
+
+// IsAd reports whether the item is an ad rather than a track.
+func (i *PlaylistItem) IsAd() bool {
+	return i.AdToken != ""
+}
+
+// Streams lists the audio streams of the item, see the function Streams.
+func (i *PlaylistItem) Streams(additionalTypes string) []Stream {
+	return Streams(i.AudioURLMap, additionalTypes, i.AdditionalAudioURL)
+}
+
+// BestAudio picks a stream of the audioUrlMap with DefaultAudioPreference.
+func (i *PlaylistItem) BestAudio() (Stream, error) {
+	return DefaultAudioPreference.Select(i.Streams(""))
+}
+
+// Duration returns the length of the track, or 0 if it was not requested.
+func (i *PlaylistItem) Duration() time.Duration {
+	return time.Duration(i.TrackLength) * time.Second
 }
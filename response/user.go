@@ -1,8 +1,12 @@
 package response
 
+import "encoding/json"
+
 type UserCanSubscribe struct {
 	CanSubscribe bool `json:"canSubscribe"`
 	IsSubscriber bool `json:"isSubscriber"`
+
+	Extra map[string]json.RawMessage `json:"-"` // fields unknown to this package
 }
 
 type UserCreateUser AuthUserLogin
@@ -10,13 +14,19 @@ type UserCreateUser AuthUserLogin
 type UserGetBookmarks struct {
 	Artists []ArtistBookmark `json:"artists"`
 	Songs   []SongBookmark   `json:"songs"`
+
+	Extra map[string]json.RawMessage `json:"-"` // fields unknown to this package
 }
 
 type UserGetStationList struct {
 	Stations StationList `json:"stations"`
 	Checksum string      `json:"checksum"`
+
+	Extra map[string]json.RawMessage `json:"-"` // fields unknown to this package
 }
 
 type UserGetStationListChecksum struct {
 	Checksum string `json:"checksum"`
+
+	Extra map[string]json.RawMessage `json:"-"` // fields unknown to this package
 }
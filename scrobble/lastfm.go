@@ -0,0 +1,156 @@
+package scrobble
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LastFMURL is the Last.fm 2.0 API endpoint.
+const LastFMURL = "https://ws.audioscrobbler.com/2.0/"
+
+// Last.fm error codes after which a request may be retried.
+var lastFMTemporary = map[int]bool{
+	8:  true, // operation failed
+	11: true, // service offline
+	16: true, // temporarily unavailable
+	29: true, // rate limit exceeded
+}
+
+// LastFM scrobbles with the Last.fm 2.0 API.
+// APIKey and Secret belong to the application, SessionKey to the user;
+// it can be obtained with Login.
+type LastFM struct {
+	APIKey     string
+	Secret     string
+	SessionKey string
+	// URL defaults to LastFMURL.
+	URL string
+	// HTTPClient defaults to http.DefaultClient.
+	HTTPClient *http.Client
+}
+
+// Login obtains a SessionKey for a Last.fm user with auth.getMobileSession.
+func (l *LastFM) Login(username, password string) error {
+	var resp struct {
+		Session struct {
+			Key string `json:"key"`
+		} `json:"session"`
+	}
+	err := l.call("auth.getMobileSession", url.Values{
+		"username": {username},
+		"password": {password},
+	}, &resp)
+	if err != nil {
+		return err
+	}
+
+	l.SessionKey = resp.Session.Key
+	return nil
+}
+
+func (l *LastFM) NowPlaying(t Track) error {
+	params := url.Values{
+		"artist": {t.Artist},
+		"track":  {t.Title},
+		"sk":     {l.SessionKey},
+	}
+	if t.Album != "" {
+		params.Set("album", t.Album)
+	}
+	if t.Duration > 0 {
+		params.Set("duration", strconv.Itoa(int(t.Duration/time.Second)))
+	}
+
+	return l.call("track.updateNowPlaying", params, nil)
+}
+
+func (l *LastFM) Scrobble(t Track, startedAt time.Time) error {
+	params := url.Values{
+		"artist[0]":    {t.Artist},
+		"track[0]":     {t.Title},
+		"timestamp[0]": {strconv.FormatInt(startedAt.Unix(), 10)},
+		"chosenByUser": {"0"},
+		"sk":           {l.SessionKey},
+	}
+	if t.Album != "" {
+		params.Set("album[0]", t.Album)
+	}
+	if t.Duration > 0 {
+		params.Set("duration[0]", strconv.Itoa(int(t.Duration/time.Second)))
+	}
+
+	return l.call("track.scrobble", params, nil)
+}
+
+// sign computes the api_sig parameter: the md5 of all parameters sorted by
+// name and concatenated as name+value, followed by the shared secret.
+func (l *LastFM) sign(params url.Values) string {
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		if k != "format" && k != "callback" {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	h := md5.New()
+	for _, k := range keys {
+		h.Write([]byte(k))
+		h.Write([]byte(params.Get(k)))
+	}
+	h.Write([]byte(l.Secret))
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func (l *LastFM) call(method string, params url.Values, data interface{}) error {
+	params.Set("method", method)
+	params.Set("api_key", l.APIKey)
+	params.Set("api_sig", l.sign(params))
+	params.Set("format", "json")
+
+	endpoint := l.URL
+	if endpoint == "" {
+		endpoint = LastFMURL
+	}
+	client := l.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Post(endpoint, "application/x-www-form-urlencoded", strings.NewReader(params.Encode()))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var wrap struct {
+		Error   int    `json:"error"`
+		Message string `json:"message"`
+	}
+	var raw json.RawMessage
+	if err := json.NewDecoder(resp.Body).Decode(&raw); err != nil {
+		if resp.StatusCode != http.StatusOK {
+			return &Error{Service: "Last.fm", Code: resp.StatusCode, Message: resp.Status, temporary: resp.StatusCode >= 500}
+		}
+		return err
+	}
+	if err := json.Unmarshal(raw, &wrap); err == nil && wrap.Error != 0 {
+		return &Error{Service: "Last.fm", Code: wrap.Error, Message: wrap.Message, temporary: lastFMTemporary[wrap.Error]}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return &Error{Service: "Last.fm", Code: resp.StatusCode, Message: resp.Status, temporary: resp.StatusCode >= 500}
+	}
+
+	if data == nil {
+		return nil
+	}
+	return json.Unmarshal(raw, data)
+}
@@ -0,0 +1,112 @@
+package scrobble
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ListenBrainzURL is the root of the public ListenBrainz API.
+const ListenBrainzURL = "https://api.listenbrainz.org"
+
+// ListenBrainz submits listens with the ListenBrainz submit-listens API.
+type ListenBrainz struct {
+	// Token is the user token from the ListenBrainz profile page.
+	Token string
+	// URL of the API root, defaults to ListenBrainzURL.
+	URL string
+	// HTTPClient defaults to http.DefaultClient.
+	HTTPClient *http.Client
+}
+
+type lbSubmission struct {
+	ListenType string     `json:"listen_type"`
+	Payload    []lbListen `json:"payload"`
+}
+
+type lbListen struct {
+	ListenedAt    int64 `json:"listened_at,omitempty"`
+	TrackMetadata struct {
+		ArtistName     string                 `json:"artist_name"`
+		TrackName      string                 `json:"track_name"`
+		ReleaseName    string                 `json:"release_name,omitempty"`
+		AdditionalInfo map[string]interface{} `json:"additional_info"`
+	} `json:"track_metadata"`
+}
+
+func (lb *ListenBrainz) NowPlaying(t Track) error {
+	return lb.submit("playing_now", t, time.Time{})
+}
+
+func (lb *ListenBrainz) Scrobble(t Track, startedAt time.Time) error {
+	return lb.submit("single", t, startedAt)
+}
+
+func (lb *ListenBrainz) submit(listenType string, t Track, startedAt time.Time) error {
+	var l lbListen
+	if !startedAt.IsZero() {
+		l.ListenedAt = startedAt.Unix()
+	}
+	l.TrackMetadata.ArtistName = t.Artist
+	l.TrackMetadata.TrackName = t.Title
+	l.TrackMetadata.ReleaseName = t.Album
+	l.TrackMetadata.AdditionalInfo = map[string]interface{}{
+		"music_service":     "pandora.com",
+		"submission_client": "gopiano",
+	}
+	if t.Duration > 0 {
+		l.TrackMetadata.AdditionalInfo["duration_ms"] = t.Duration.Milliseconds()
+	}
+
+	body, err := json.Marshal(lbSubmission{
+		ListenType: listenType,
+		Payload:    []lbListen{l},
+	})
+	if err != nil {
+		return err
+	}
+
+	root := lb.URL
+	if root == "" {
+		root = ListenBrainzURL
+	}
+	req, err := http.NewRequest("POST", strings.TrimSuffix(root, "/")+"/1/submit-listens", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Token "+lb.Token)
+	req.Header.Set("Content-Type", "application/json")
+
+	client := lb.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	e := &Error{
+		Service:   "ListenBrainz",
+		Code:      resp.StatusCode,
+		temporary: resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500,
+	}
+	var msg struct {
+		Error string `json:"error"`
+	}
+	data, _ := ioutil.ReadAll(resp.Body)
+	if json.Unmarshal(data, &msg) == nil && msg.Error != "" {
+		e.Message = msg.Error
+	} else {
+		e.Message = resp.Status
+	}
+	return e
+}
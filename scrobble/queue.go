@@ -0,0 +1,123 @@
+package scrobble
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+)
+
+// DefaultQueueSize is the number of listens a Queue keeps if MaxSize is not set.
+const DefaultQueueSize = 1000
+
+// Listen is a scrobble waiting in a Queue.
+type Listen struct {
+	Track     Track     `json:"track"`
+	StartedAt time.Time `json:"startedAt"`
+}
+
+// Queue wraps a Scrobbler and keeps scrobbles that could not be delivered,
+// e.g. while offline, to submit them again later in their original order.
+//
+// NowPlaying is passed through as is since it is worthless when late.
+type Queue struct {
+	Scrobbler
+
+	// MaxSize limits the number of pending listens, dropping the oldest.
+	// Defaults to DefaultQueueSize.
+	MaxSize int
+
+	mu      sync.Mutex
+	pending []Listen
+}
+
+// NewQueue returns a Queue around s.
+func NewQueue(s Scrobbler) *Queue {
+	return &Queue{Scrobbler: s}
+}
+
+// Scrobble flushes pending listens and submits this one. If the service
+// cannot be reached the listen is queued and nil is returned; errors are
+// only returned for listens the service rejected.
+func (q *Queue) Scrobble(t Track, startedAt time.Time) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.push(Listen{Track: t, StartedAt: startedAt})
+	return q.flush()
+}
+
+// Flush submits pending listens until one fails temporarily. Listens the
+// service rejects are dropped and the last such error is returned.
+func (q *Queue) Flush() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return q.flush()
+}
+
+func (q *Queue) flush() (rejected error) {
+	for len(q.pending) > 0 {
+		l := q.pending[0]
+		if err := q.Scrobbler.Scrobble(l.Track, l.StartedAt); err != nil && temporary(err) {
+			return rejected
+		} else if err != nil {
+			rejected = err
+		}
+		q.pending = q.pending[1:]
+	}
+	q.pending = nil
+
+	return rejected
+}
+
+func (q *Queue) push(l Listen) {
+	max := q.MaxSize
+	if max <= 0 {
+		max = DefaultQueueSize
+	}
+	if len(q.pending) >= max {
+		q.pending = q.pending[len(q.pending)-max+1:]
+	}
+	q.pending = append(q.pending, l)
+}
+
+// Pending returns the listens waiting to be submitted.
+func (q *Queue) Pending() []Listen {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return append([]Listen(nil), q.pending...)
+}
+
+// Save writes the pending listens as JSON so they survive a restart.
+func (q *Queue) Save(w io.Writer) error {
+	return json.NewEncoder(w).Encode(q.Pending())
+}
+
+// Load adds listens written by Save in front of the pending ones.
+func (q *Queue) Load(r io.Reader) error {
+	var listens []Listen
+	if err := json.NewDecoder(r).Decode(&listens); err != nil {
+		return err
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	pending := q.pending
+	q.pending = nil
+	for _, l := range append(listens, pending...) {
+		q.push(l)
+	}
+	return nil
+}
+
+// temporary reports whether err may go away by itself. Anything that
+// is not an error reported by the service, e.g. a network error, is.
+func temporary(err error) bool {
+	if e, ok := err.(*Error); ok {
+		return e.Temporary()
+	}
+	return true
+}
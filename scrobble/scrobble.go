@@ -0,0 +1,70 @@
+/*
+Package scrobble submits played tracks to scrobbling services such as
+Last.fm and ListenBrainz.
+*/
+package scrobble
+
+import (
+	"fmt"
+	"time"
+
+	"denniskupec.com/gopiano/response"
+)
+
+// Scrobbler submits tracks to a scrobbling service.
+type Scrobbler interface {
+	// NowPlaying announces a track that just started playing.
+	NowPlaying(t Track) error
+	// Scrobble records a track that started playing at startedAt.
+	Scrobble(t Track, startedAt time.Time) error
+}
+
+// Track is the metadata of a played track.
+type Track struct {
+	Artist     string        `json:"artist"`
+	Album      string        `json:"album,omitempty"`
+	Title      string        `json:"title"`
+	Duration   time.Duration `json:"duration,omitempty"` // 0 if unknown
+	TrackToken string        `json:"trackToken,omitempty"`
+}
+
+// TracksFromPlaylist returns the tracks of a playlist, leaving out ads.
+func TracksFromPlaylist(p *response.StationGetPlaylist) []Track {
+	var tracks []Track
+	for i := range p.Items {
+		if p.Items[i].IsAd() || p.Items[i].SongName == "" {
+			continue
+		}
+		tracks = append(tracks, TrackFromItem(&p.Items[i]))
+	}
+	return tracks
+}
+
+// TrackFromItem returns the track of a playlist item.
+func TrackFromItem(item *response.PlaylistItem) Track {
+	return Track{
+		Artist:     item.ArtistName,
+		Album:      item.AlbumName,
+		Title:      item.SongName,
+		Duration:   item.Duration(),
+		TrackToken: item.TrackToken,
+	}
+}
+
+// Error is an error reported by a scrobbling service.
+type Error struct {
+	Service string
+	Code    int
+	Message string
+
+	temporary bool
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s error %d: %s", e.Service, e.Code, e.Message)
+}
+
+// Temporary reports whether submitting again later may succeed.
+func (e *Error) Temporary() bool {
+	return e.temporary
+}
@@ -0,0 +1,157 @@
+package scrobble
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+var track = Track{Artist: "Artist", Album: "Album", Title: "Title", Duration: 3 * time.Minute}
+
+func TestListenBrainz(t *testing.T) {
+	var got lbSubmission
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/1/submit-listens" || r.Header.Get("Authorization") != "Token secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"code": 401, "error": "Invalid authorization token."}`))
+			return
+		}
+		got = lbSubmission{}
+		json.NewDecoder(r.Body).Decode(&got)
+	}))
+	defer srv.Close()
+
+	lb := &ListenBrainz{Token: "secret", URL: srv.URL}
+	started := time.Unix(1500000000, 0)
+	if err := lb.Scrobble(track, started); err != nil {
+		t.Fatal(err)
+	}
+	if got.ListenType != "single" || len(got.Payload) != 1 {
+		t.Fatalf("unexpected submission %+v", got)
+	}
+	l := got.Payload[0]
+	if l.ListenedAt != started.Unix() || l.TrackMetadata.ArtistName != "Artist" || l.TrackMetadata.ReleaseName != "Album" {
+		t.Errorf("unexpected listen %+v", l)
+	}
+
+	if err := lb.NowPlaying(track); err != nil {
+		t.Fatal(err)
+	}
+	if got.ListenType != "playing_now" || got.Payload[0].ListenedAt != 0 {
+		t.Errorf("unexpected submission %+v", got)
+	}
+
+	lb.Token = "wrong"
+	err := lb.NowPlaying(track)
+	if e, ok := err.(*Error); !ok || e.Code != 401 || e.Temporary() {
+		t.Errorf("expected permanent 401 error, got %v", err)
+	}
+}
+
+func TestLastFM(t *testing.T) {
+	l := &LastFM{APIKey: "key", Secret: "secret"}
+	server := *l
+
+	var got url.Values
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		got = r.PostForm
+
+		sig := got.Get("api_sig")
+		got.Del("api_sig")
+		if sig != server.sign(got) {
+			w.Write([]byte(`{"error": 13, "message": "Invalid method signature supplied"}`))
+			return
+		}
+		switch got.Get("method") {
+		case "auth.getMobileSession":
+			w.Write([]byte(`{"session": {"name": "user", "key": "session-key"}}`))
+		default:
+			w.Write([]byte(`{}`))
+		}
+	}))
+	defer srv.Close()
+	l.URL = srv.URL
+
+	if err := l.Login("user", "pass"); err != nil {
+		t.Fatal(err)
+	}
+	if l.SessionKey != "session-key" {
+		t.Fatalf("expected session key, got %q", l.SessionKey)
+	}
+
+	if err := l.Scrobble(track, time.Unix(1500000000, 0)); err != nil {
+		t.Fatal(err)
+	}
+	if got.Get("method") != "track.scrobble" || got.Get("timestamp[0]") != "1500000000" || got.Get("duration[0]") != "180" || got.Get("sk") != "session-key" {
+		t.Errorf("unexpected parameters %v", got)
+	}
+
+	l.Secret = "wrong"
+	if err := l.NowPlaying(track); err == nil {
+		t.Error("expected signature error")
+	}
+}
+
+func TestLastFMSign(t *testing.T) {
+	l := &LastFM{Secret: "mysecret"}
+	sig := l.sign(url.Values{
+		"api_key": {"xxxxxxxx"},
+		"method":  {"auth.getSession"},
+		"token":   {"yyyyyy"},
+		"format":  {"json"},
+	})
+	// md5("api_keyxxxxxxxxmethodauth.getSessiontokenyyyyyymysecret")
+	if sig != "f462da5c166769c6c6014860b36a02af" {
+		t.Errorf("unexpected signature %q", sig)
+	}
+}
+
+type flaky struct {
+	fail      error
+	scrobbled []Track
+}
+
+func (f *flaky) NowPlaying(t Track) error { return f.fail }
+
+func (f *flaky) Scrobble(t Track, startedAt time.Time) error {
+	if f.fail != nil {
+		return f.fail
+	}
+	f.scrobbled = append(f.scrobbled, t)
+	return nil
+}
+
+func TestQueue(t *testing.T) {
+	f := &flaky{fail: &Error{Code: 16, temporary: true}}
+	q := NewQueue(f)
+	q.MaxSize = 2
+
+	for _, title := range []string{"a", "b", "c"} {
+		if err := q.Scrobble(Track{Title: title}, time.Now()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := len(q.Pending()); n != 2 {
+		t.Fatalf("expected 2 pending, got %d", n)
+	}
+
+	f.fail = nil
+	if err := q.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if len(f.scrobbled) != 2 || f.scrobbled[0].Title != "b" || f.scrobbled[1].Title != "c" {
+		t.Errorf("unexpected scrobbles %+v", f.scrobbled)
+	}
+
+	f.fail = &Error{Code: 6}
+	if err := q.Scrobble(Track{Title: "d"}, time.Now()); err != f.fail {
+		t.Errorf("expected rejection, got %v", err)
+	}
+	if n := len(q.Pending()); n != 0 {
+		t.Errorf("expected rejected listen to be dropped, %d pending", n)
+	}
+}
@@ -0,0 +1,201 @@
+package gopiano
+
+import (
+	"errors"
+	"fmt"
+
+	"denniskupec.com/gopiano/response"
+)
+
+// Seed is a song or artist seed of a station.
+type Seed struct {
+	SeedID     string
+	MusicToken string
+	ArtistName string
+	SongName   string // empty for artist seeds
+}
+
+func (s Seed) music() BackupMusic {
+	return BackupMusic{ArtistName: s.ArtistName, SongName: s.SongName}
+}
+
+func (s Seed) String() string {
+	return s.music().String()
+}
+
+// SharedSeed is a seed that several stations have.
+type SharedSeed struct {
+	Music    BackupMusic
+	Stations []*response.Station
+}
+
+// SeedError is returned by StationReplaceSeeds if a seed could not be
+// added or removed.
+type SeedError struct {
+	Op   string // "add" or "remove"
+	Seed Seed   // only MusicToken is set for seeds to add
+	Err  error
+
+	// Rollback lists the seeds added before a failed add that could not be
+	// removed again, so the station still has them.
+	Rollback []*SeedError
+}
+
+func (e *SeedError) Error() string {
+	name := e.Seed.String()
+	if e.Seed.ArtistName == "" {
+		name = e.Seed.MusicToken
+	}
+	msg := fmt.Sprintf("%s seed %s: %v", e.Op, name, e.Err)
+	for _, r := range e.Rollback {
+		msg += "; rollback: " + r.Error()
+	}
+	return msg
+}
+
+func (e *SeedError) Unwrap() error {
+	return e.Err
+}
+
+func stationSeeds(s *response.Station) []Seed {
+	var seeds []Seed
+	for _, a := range s.Music.Artists {
+		seeds = append(seeds, Seed{SeedID: a.SeedID, MusicToken: a.MusicToken, ArtistName: a.ArtistName})
+	}
+	for _, song := range s.Music.Songs {
+		seeds = append(seeds, Seed{SeedID: song.SeedID, MusicToken: song.MusicToken, ArtistName: song.ArtistName, SongName: song.SongName})
+	}
+	return seeds
+}
+
+// StationSeeds returns the seeds of a station, artists first.
+func (c *Client) StationSeeds(stationToken string) ([]Seed, error) {
+	resp, err := c.StationGetStation(stationToken, true)
+	if err != nil {
+		return nil, err
+	}
+	return stationSeeds(&resp.Result), nil
+}
+
+// StationReplaceSeeds makes the music identified by musicTokens, obtained
+// from Client.MusicSearch or Seed.MusicToken, the seeds of a station.
+//
+// Seeds the station already has are kept. The new seeds are added before
+// the old ones are removed, so the station always has seeds. If adding
+// fails, the seeds added so far are removed again, leaving the station as
+// it was unless that fails too, see SeedError.Rollback. If removing fails,
+// the station has the new seeds and some of the old ones. Either way the
+// error is a *SeedError.
+func (c *Client) StationReplaceSeeds(stationToken string, musicTokens ...string) error {
+	if len(musicTokens) == 0 {
+		return errors.New("a station needs at least one seed")
+	}
+
+	seeds, err := c.StationSeeds(stationToken)
+	if err != nil {
+		return err
+	}
+
+	keep := make(map[string]bool)
+	for _, token := range musicTokens {
+		keep[token] = true
+	}
+	have := make(map[string]bool)
+	for _, s := range seeds {
+		have[s.MusicToken] = true
+	}
+
+	var added []Seed
+	for _, token := range musicTokens {
+		if have[token] {
+			continue
+		}
+		resp, err := c.StationAddMusic(token, stationToken)
+		if err != nil {
+			se := &SeedError{Op: "add", Seed: Seed{MusicToken: token}, Err: err}
+			for _, s := range added {
+				if err := c.StationDeleteMusic(s.SeedID); err != nil {
+					se.Rollback = append(se.Rollback, &SeedError{Op: "remove", Seed: s, Err: err})
+				}
+			}
+			return se
+		}
+		have[token] = true
+		added = append(added, Seed{SeedID: resp.SeedID, MusicToken: token})
+	}
+
+	for _, s := range seeds {
+		if keep[s.MusicToken] {
+			continue
+		}
+		if err := c.StationDeleteMusic(s.SeedID); err != nil {
+			return &SeedError{Op: "remove", Seed: s, Err: err}
+		}
+	}
+
+	return nil
+}
+
+// DuplicateSeeds groups seeds for the same song, or the same artist, by
+// name ignoring case. Only groups of two or more seeds are returned.
+func DuplicateSeeds(seeds []Seed) [][]Seed {
+	var keys []string
+	groups := make(map[string][]Seed)
+	for _, s := range seeds {
+		key := musicKey(s.music())
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], s)
+	}
+
+	var dups [][]Seed
+	for _, key := range keys {
+		if len(groups[key]) > 1 {
+			dups = append(dups, groups[key])
+		}
+	}
+	return dups
+}
+
+// SharedSeeds finds the seeds that several stations have, as fetched with
+// StationGetStation including extended attributes. Seeds are matched by
+// name, ignoring case, and listed in the order first seen.
+func SharedSeeds(stations []response.Station) []SharedSeed {
+	var keys []string
+	shared := make(map[string]*SharedSeed)
+	for i := range stations {
+		s := &stations[i]
+		seen := make(map[string]bool)
+		for _, seed := range stationSeeds(s) {
+			key := musicKey(seed.music())
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			if shared[key] == nil {
+				keys = append(keys, key)
+				shared[key] = &SharedSeed{Music: seed.music()}
+			}
+			shared[key].Stations = append(shared[key].Stations, s)
+		}
+	}
+
+	var list []SharedSeed
+	for _, key := range keys {
+		if len(shared[key].Stations) > 1 {
+			list = append(list, *shared[key])
+		}
+	}
+	return list
+}
+
+// SharedSeeds finds the seeds that several of the user's stations have.
+func (c *Client) SharedSeeds() ([]SharedSeed, error) {
+	stations, _, err := c.extendedStations()
+	if err != nil {
+		return nil, err
+	}
+	return SharedSeeds(stations), nil
+}
@@ -0,0 +1,129 @@
+package gopiano
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"denniskupec.com/gopiano/request"
+	"denniskupec.com/gopiano/response"
+)
+
+func TestDuplicateSeeds(t *testing.T) {
+	seeds := []Seed{
+		{SeedID: "1", ArtistName: "Queen"},
+		{SeedID: "2", ArtistName: "Queen", SongName: "Bohemian Rhapsody"},
+		{SeedID: "3", ArtistName: "queen "},
+		{SeedID: "4", ArtistName: "Queen", SongName: "bohemian rhapsody"},
+		{SeedID: "5", ArtistName: "Queen", SongName: "Killer Queen"},
+	}
+
+	var got []string
+	for _, group := range DuplicateSeeds(seeds) {
+		var ids []string
+		for _, s := range group {
+			ids = append(ids, s.SeedID)
+		}
+		got = append(got, strings.Join(ids, ","))
+	}
+	expected := "1,3 2,4"
+	if strings.Join(got, " ") != expected {
+		t.Errorf("\nexpected:\n\t%q\ngot:\n\t%q", expected, got)
+	}
+}
+
+func TestSharedSeeds(t *testing.T) {
+	stations := []response.Station{
+		{StationName: "A", Music: response.StationMusic{
+			Artists: []response.ArtistSeed{{ArtistName: "Queen"}, {ArtistName: "Queen"}},
+			Songs:   []response.SongSeed{{ArtistName: "Muse", SongName: "Uprising"}},
+		}},
+		{StationName: "B", Music: response.StationMusic{
+			Artists: []response.ArtistSeed{{ArtistName: "Muse"}},
+		}},
+		{StationName: "C", Music: response.StationMusic{
+			Artists: []response.ArtistSeed{{ArtistName: "QUEEN"}},
+			Songs:   []response.SongSeed{{ArtistName: "Muse", SongName: "Uprising"}},
+		}},
+	}
+
+	var got []string
+	for _, s := range SharedSeeds(stations) {
+		var names []string
+		for _, st := range s.Stations {
+			names = append(names, st.StationName)
+		}
+		got = append(got, s.Music.String()+": "+strings.Join(names, ","))
+	}
+	expected := []string{"Queen: A,C", "Muse - Uprising: A,C"}
+	if strings.Join(got, "; ") != strings.Join(expected, "; ") {
+		t.Errorf("\nexpected:\n\t%q\ngot:\n\t%q", expected, got)
+	}
+}
+
+func TestStationReplaceSeeds(t *testing.T) {
+	var calls []string
+	failAdd, failDelete := "", ""
+	c, _ := NewClient(AndroidClient)
+	c.Interceptors = []Interceptor{func(req request.Type, data interface{}, next Invoker) error {
+		switch r := req.(type) {
+		case request.GetStation:
+			data.(*response.StationGetStation).Result = response.Station{Music: response.StationMusic{
+				Artists: []response.ArtistSeed{{SeedID: "s1", MusicToken: "R1", ArtistName: "Queen"}},
+				Songs:   []response.SongSeed{{SeedID: "s2", MusicToken: "S2", ArtistName: "Muse", SongName: "Uprising"}},
+			}}
+		case request.AddMusic:
+			calls = append(calls, "add "+r.MusicToken)
+			if r.MusicToken == failAdd {
+				return errors.New("failed")
+			}
+			data.(*response.StationAddMusic).SeedID = "new-" + r.MusicToken
+		case request.DeleteMusic:
+			calls = append(calls, "delete "+r.SeedID)
+			if r.SeedID == failDelete {
+				return errors.New("failed")
+			}
+		}
+		return nil
+	}}
+
+	if err := c.StationReplaceSeeds("st", "S2", "R3", "R3"); err != nil {
+		t.Fatal(err)
+	}
+	expected := "add R3; delete s1"
+	if got := strings.Join(calls, "; "); got != expected {
+		t.Errorf("\nexpected:\n\t%q\ngot:\n\t%q", expected, got)
+	}
+
+	calls, failAdd = nil, "R5"
+	err := c.StationReplaceSeeds("st", "R4", "R5")
+	if se, ok := err.(*SeedError); !ok || se.Op != "add" || se.Seed.MusicToken != "R5" {
+		t.Errorf("expected add error for R5, got %v", err)
+	}
+	expected = "add R4; add R5; delete new-R4"
+	if got := strings.Join(calls, "; "); got != expected {
+		t.Errorf("\nexpected:\n\t%q\ngot:\n\t%q", expected, got)
+	}
+
+	// Seeds that cannot be removed again are reported with the add error.
+	calls, failAdd, failDelete = nil, "R7", "new-R5"
+	err = c.StationReplaceSeeds("st", "R4", "R5", "R6", "R7")
+	se, ok := err.(*SeedError)
+	if !ok || se.Op != "add" || se.Seed.MusicToken != "R7" {
+		t.Fatalf("expected add error for R7, got %v", err)
+	}
+	if len(se.Rollback) != 1 || se.Rollback[0].Seed.SeedID != "new-R5" || se.Rollback[0].Seed.MusicToken != "R5" {
+		t.Errorf("expected new-R5 to be left over, got %v", se.Rollback)
+	}
+	expected = "add R4; add R5; add R6; add R7; delete new-R4; delete new-R5; delete new-R6"
+	if got := strings.Join(calls, "; "); got != expected {
+		t.Errorf("\nexpected:\n\t%q\ngot:\n\t%q", expected, got)
+	}
+	if msg := err.Error(); msg != "add seed R7: failed; rollback: remove seed R5: failed" {
+		t.Errorf("unexpected message %q", msg)
+	}
+
+	if err := c.StationReplaceSeeds("st"); err == nil {
+		t.Error("expected error for no seeds")
+	}
+}
@@ -1,18 +1,23 @@
 package gopiano
 
 import (
+	"strings"
+
 	"denniskupec.com/gopiano/request"
 	"denniskupec.com/gopiano/response"
 )
 
 // StationAddFeedback adds feedback (thumbs up or down, or star or ban if you prefer) to a song.
+// Argument stationToken is the station the feedback is given on, obtained from Client.UserGetStationList
 // Argument trackToken is the token identifying a track. Obtained from Client.StationGetPlaylist
 // Argument isPositive is a bool which if true is a "star" and if false is a "ban".
-func (c *Client) StationAddFeedback(trackToken string, isPositive bool) (*response.StationAddFeedback, error) {
+// The FeedbackID of the result can be passed to Client.StationDeleteFeedback to take the feedback back.
+func (c *Client) StationAddFeedback(stationToken, trackToken string, isPositive bool) (*response.StationAddFeedback, error) {
 	requestData := request.AddFeedback{
-		TrackToken: trackToken,
-		IsPositive: isPositive,
-		UserToken:  c.Token(),
+		StationToken: stationToken,
+		TrackToken:   trackToken,
+		IsPositive:   isPositive,
+		UserToken:    c.Token(),
 	}
 
 	var resp response.StationAddFeedback
@@ -100,13 +105,40 @@ func (c *Client) StationGetGenreStations() (*response.StationGetGenreStations, e
 	return &resp, c.Call(requestData, &resp)
 }
 
+// StationGetGenreStationsChecksum returns the checksum of the genre stations,
+// which changes whenever Pandora changes them.
+func (c *Client) StationGetGenreStationsChecksum() (*response.StationGetGenreStationsChecksum, error) {
+	requestData := request.GetGenreStationsChecksum{
+		UserToken: c.Token(),
+	}
+
+	var resp response.StationGetGenreStationsChecksum
+	return &resp, c.Call(requestData, &resp)
+}
+
 // StationGetPlaylist retrieves a playlist for a specified token.
 // Argument stationToken is a obtained from User.GetStationList.
 // Note: an error response with code 0 may mean you've called getPlaylist too much.
 func (c *Client) StationGetPlaylist(stationToken string) (*response.StationGetPlaylist, error) {
 	requestData := request.GetPlaylist{
-		StationToken: stationToken,
-		UserToken:    c.Token(),
+		StationToken:       stationToken,
+		IncludeTrackLength: true,
+		UserToken:          c.Token(),
+	}
+
+	var resp response.StationGetPlaylist
+	return &resp, c.Call(requestData, &resp)
+}
+
+// StationGetPlaylistAudio is StationGetPlaylist additionally asking for the given
+// stream types, such as "HTTP_128_MP3". Each item lists their URLs in AdditionalAudioURL,
+// see response.Streams.
+func (c *Client) StationGetPlaylistAudio(stationToken string, audioTypes ...string) (*response.StationGetPlaylist, error) {
+	requestData := request.GetPlaylist{
+		StationToken:       stationToken,
+		AdditionalAudioURL: strings.Join(audioTypes, ","),
+		IncludeTrackLength: true,
+		UserToken:          c.Token(),
 	}
 
 	var resp response.StationGetPlaylist
@@ -0,0 +1,332 @@
+package gopiano
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"denniskupec.com/gopiano/response"
+)
+
+// SyncDirection selects which account a sync changes.
+type SyncDirection int
+
+const (
+	// SyncOneWay makes the second account match the first.
+	SyncOneWay SyncDirection = iota
+	// SyncBidirectional copies what is missing in either account to the other.
+	SyncBidirectional
+)
+
+// SyncConflict decides how songs rated thumbs up in one account and thumbs
+// down in the other are handled. Neither rating can be replaced, see
+// ErrFeedbackNotRestorable, so both are always kept.
+type SyncConflict int
+
+const (
+	// ConflictSkip only lists conflicts in the StationDiff.
+	ConflictSkip SyncConflict = iota
+	// ConflictReport also returns each conflict as a problem, with
+	// ErrRatingConflict.
+	ConflictReport
+)
+
+// ErrRatingConflict is reported by SyncStations for songs rated differently
+// in the two accounts if SyncOptions.Conflicts is ConflictReport.
+var ErrRatingConflict = errors.New("song is rated differently in the other account")
+
+// SyncOptions configure SyncStations.
+type SyncOptions struct {
+	Direction SyncDirection
+	Conflicts SyncConflict
+	// DryRun only computes the diff.
+	DryRun bool
+	// Prune removes seeds and feedback from the second account that the
+	// first does not have. Only used with SyncOneWay; stations are never deleted.
+	Prune bool
+}
+
+// StationDiff lists the differences between the stations of two accounts, A and B.
+type StationDiff struct {
+	OnlyA   []response.Station
+	OnlyB   []response.Station
+	Changed []StationChange
+}
+
+// StationChange lists the differences between two stations matched by name or seeds.
+type StationChange struct {
+	A, B response.Station
+
+	SeedsOnlyA []Seed
+	SeedsOnlyB []Seed
+
+	FeedbackOnlyA []response.FeedbackResponse
+	FeedbackOnlyB []response.FeedbackResponse
+
+	// Conflicts holds songs rated differently, A's feedback first.
+	Conflicts [][2]response.FeedbackResponse
+}
+
+// Empty reports whether both accounts have the same stations.
+func (d *StationDiff) Empty() bool {
+	return len(d.OnlyA) == 0 && len(d.OnlyB) == 0 && len(d.Changed) == 0
+}
+
+func (d *StationDiff) String() string {
+	var b strings.Builder
+	for _, s := range d.OnlyA {
+		fmt.Fprintf(&b, "+ %q only in A\n", s.StationName)
+	}
+	for _, s := range d.OnlyB {
+		fmt.Fprintf(&b, "- %q only in B\n", s.StationName)
+	}
+	for _, c := range d.Changed {
+		if c.A.StationName == c.B.StationName {
+			fmt.Fprintf(&b, "~ %q\n", c.A.StationName)
+		} else {
+			fmt.Fprintf(&b, "~ %q (%q in B)\n", c.A.StationName, c.B.StationName)
+		}
+		for _, s := range c.SeedsOnlyA {
+			fmt.Fprintf(&b, "    + seed %s\n", s.music())
+		}
+		for _, s := range c.SeedsOnlyB {
+			fmt.Fprintf(&b, "    - seed %s\n", s.music())
+		}
+		for _, f := range c.FeedbackOnlyA {
+			fmt.Fprintf(&b, "    + %s %s\n", thumb(f.IsPositive), feedbackMusic(f))
+		}
+		for _, f := range c.FeedbackOnlyB {
+			fmt.Fprintf(&b, "    - %s %s\n", thumb(f.IsPositive), feedbackMusic(f))
+		}
+		for _, f := range c.Conflicts {
+			fmt.Fprintf(&b, "    ! %s: %s in A, %s in B\n", feedbackMusic(f[0]), thumb(f[0].IsPositive), thumb(f[1].IsPositive))
+		}
+	}
+	return b.String()
+}
+
+func thumb(isPositive bool) string {
+	if isPositive {
+		return "thumbs up"
+	}
+	return "thumbs down"
+}
+
+func feedbackMusic(f response.FeedbackResponse) BackupMusic {
+	return BackupMusic{ArtistName: f.ArtistName, SongName: f.SongName}
+}
+
+// musicKey identifies music across accounts.
+func musicKey(m BackupMusic) string {
+	return strings.ToLower(strings.TrimSpace(m.ArtistName)) + "\x00" + strings.ToLower(strings.TrimSpace(m.SongName))
+}
+
+// seedSet returns the sorted keys of a station's seeds.
+func seedSet(s *response.Station) string {
+	var keys []string
+	for _, seed := range stationSeeds(s) {
+		keys = append(keys, musicKey(seed.music()))
+	}
+	sort.Strings(keys)
+	return strings.Join(keys, "\x01")
+}
+
+// DiffStations compares two accounts' stations, as fetched with
+// StationGetStation including extended attributes. Stations are matched by
+// name, ignoring case, or else by having the same seeds.
+func DiffStations(a, b []response.Station) *StationDiff {
+	d := &StationDiff{}
+
+	matched := make([]bool, len(b))
+	match := func(same func(x, y *response.Station) bool, x *response.Station) int {
+		for j := range b {
+			if !matched[j] && same(x, &b[j]) {
+				matched[j] = true
+				return j
+			}
+		}
+		return -1
+	}
+
+	pairs := make([]int, len(a))
+	for i := range a {
+		pairs[i] = match(func(x, y *response.Station) bool {
+			return strings.EqualFold(strings.TrimSpace(x.StationName), strings.TrimSpace(y.StationName))
+		}, &a[i])
+	}
+	for i := range a {
+		if pairs[i] < 0 {
+			pairs[i] = match(func(x, y *response.Station) bool {
+				seeds := seedSet(x)
+				return seeds != "" && seeds == seedSet(y)
+			}, &a[i])
+		}
+	}
+
+	for i, j := range pairs {
+		if j < 0 {
+			d.OnlyA = append(d.OnlyA, a[i])
+		} else if c := diffStation(&a[i], &b[j]); c != nil {
+			d.Changed = append(d.Changed, *c)
+		}
+	}
+	for j := range b {
+		if !matched[j] {
+			d.OnlyB = append(d.OnlyB, b[j])
+		}
+	}
+
+	return d
+}
+
+func diffStation(a, b *response.Station) *StationChange {
+	c := &StationChange{A: *a, B: *b}
+
+	seedsA, seedsB := stationSeeds(a), stationSeeds(b)
+	c.SeedsOnlyA = seedsMissing(seedsA, seedsB)
+	c.SeedsOnlyB = seedsMissing(seedsB, seedsA)
+
+	feedbackA := append(append([]response.FeedbackResponse(nil), a.Feedback.ThumbsUp...), a.Feedback.ThumbsDown...)
+	feedbackB := append(append([]response.FeedbackResponse(nil), b.Feedback.ThumbsUp...), b.Feedback.ThumbsDown...)
+
+	ratedB := make(map[string]response.FeedbackResponse)
+	for _, f := range feedbackB {
+		ratedB[musicKey(feedbackMusic(f))] = f
+	}
+	ratedA := make(map[string]bool)
+	for _, f := range feedbackA {
+		key := musicKey(feedbackMusic(f))
+		ratedA[key] = true
+
+		if other, ok := ratedB[key]; !ok {
+			c.FeedbackOnlyA = append(c.FeedbackOnlyA, f)
+		} else if other.IsPositive != f.IsPositive {
+			c.Conflicts = append(c.Conflicts, [2]response.FeedbackResponse{f, other})
+		}
+	}
+	for _, f := range feedbackB {
+		if !ratedA[musicKey(feedbackMusic(f))] {
+			c.FeedbackOnlyB = append(c.FeedbackOnlyB, f)
+		}
+	}
+
+	if a.StationName == b.StationName && len(c.SeedsOnlyA) == 0 && len(c.SeedsOnlyB) == 0 &&
+		len(c.FeedbackOnlyA) == 0 && len(c.FeedbackOnlyB) == 0 && len(c.Conflicts) == 0 {
+		return nil
+	}
+	return c
+}
+
+// seedsMissing returns the seeds of x that y does not have.
+func seedsMissing(x, y []Seed) []Seed {
+	have := make(map[string]bool)
+	for _, s := range y {
+		have[musicKey(s.music())] = true
+	}
+
+	var missing []Seed
+	for _, s := range x {
+		if !have[musicKey(s.music())] {
+			missing = append(missing, s)
+		}
+	}
+	return missing
+}
+
+// SyncStations compares the stations of the accounts logged in with a and b
+// and, unless opts.DryRun is set, applies the difference. Anything that
+// cannot be copied is returned as a problem instead of stopping the sync.
+// Feedback cannot be copied, see ErrFeedbackNotRestorable, so missing
+// ratings are reported and existing ones are kept, including conflicting
+// ones, see SyncConflict.
+func SyncStations(a, b *Client, opts SyncOptions) (*StationDiff, []ImportProblem, error) {
+	stationsA, _, err := a.extendedStations()
+	if err != nil {
+		return nil, nil, err
+	}
+	stationsB, _, err := b.extendedStations()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	d := DiffStations(stationsA, stationsB)
+	if opts.DryRun {
+		return d, nil, nil
+	}
+
+	report := &ImportReport{}
+	twoWay := opts.Direction == SyncBidirectional
+
+	for _, s := range d.OnlyA {
+		b.syncStation(&s, report)
+	}
+	if twoWay {
+		for _, s := range d.OnlyB {
+			a.syncStation(&s, report)
+		}
+	}
+
+	for _, c := range d.Changed {
+		b.syncChange(&c.B, c.SeedsOnlyA, c.FeedbackOnlyA, report)
+		if twoWay {
+			a.syncChange(&c.A, c.SeedsOnlyB, c.FeedbackOnlyB, report)
+		} else if opts.Prune {
+			b.prune(&c.B, c.SeedsOnlyB, c.FeedbackOnlyB, report)
+		}
+
+		if opts.Conflicts == ConflictReport {
+			for _, f := range c.Conflicts {
+				report.Problems = append(report.Problems, ImportProblem{Station: c.B.StationName, Music: feedbackMusic(f[1]), Err: ErrRatingConflict})
+			}
+		}
+	}
+
+	return d, report.Problems, nil
+}
+
+// syncStation creates a copy of s.
+func (c *Client) syncStation(s *response.Station, report *ImportReport) {
+	if _, err := c.importStation(backupStation(s, false), report); err != nil {
+		report.Problems = append(report.Problems, ImportProblem{Station: s.StationName, Err: err})
+	}
+}
+
+// syncChange adds seeds and feedback to station s.
+func (c *Client) syncChange(s *response.Station, seeds []Seed, feedback []response.FeedbackResponse, report *ImportReport) {
+	for _, seed := range seeds {
+		token, err := c.findMusic(seed.music())
+		if err == nil {
+			_, err = c.StationAddMusic(token, s.StationToken)
+		}
+		if err != nil {
+			report.Problems = append(report.Problems, ImportProblem{Station: s.StationName, Music: seed.music(), Err: err})
+		}
+	}
+
+	for _, f := range feedback {
+		report.Problems = append(report.Problems, ImportProblem{Station: s.StationName, Music: feedbackMusic(f), Err: ErrFeedbackNotRestorable})
+	}
+}
+
+// prune removes seeds and feedback from station s, keeping at least one seed.
+func (c *Client) prune(s *response.Station, seeds []Seed, feedback []response.FeedbackResponse, report *ImportReport) {
+	left := len(stationSeeds(s))
+	for _, seed := range seeds {
+		err := errors.New("cannot remove the last seed")
+		if left > 1 {
+			if err = c.StationDeleteMusic(seed.SeedID); err == nil {
+				left--
+			}
+		}
+		if err != nil {
+			report.Problems = append(report.Problems, ImportProblem{Station: s.StationName, Music: seed.music(), Err: err})
+		}
+	}
+
+	for _, f := range feedback {
+		if err := c.StationDeleteFeedback(f.FeedbackID); err != nil {
+			report.Problems = append(report.Problems, ImportProblem{Station: s.StationName, Music: feedbackMusic(f), Err: err})
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package gopiano
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"denniskupec.com/gopiano/request"
+	"denniskupec.com/gopiano/response"
+)
+
+func TestDiffStations(t *testing.T) {
+	var a, b []response.Station
+	err := json.Unmarshal([]byte(`[
+		{"stationName": "Jazz", "music": {"artists": [{"seedId": "a1", "artistName": "Miles Davis"}]},
+		 "feedback": {"thumbsUp": [{"artistName": "Miles Davis", "songName": "So What", "isPositive": true}]}},
+		{"stationName": "My Rock", "music": {"songs": [{"seedId": "a2", "artistName": "Band", "songName": "Hit"}]}},
+		{"stationName": "Only A", "music": {"artists": [{"seedId": "a3", "artistName": "Solo"}]}}
+	]`), &a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = json.Unmarshal([]byte(`[
+		{"stationName": "jazz", "music": {"artists": [{"seedId": "b1", "artistName": "miles davis"}, {"seedId": "b2", "artistName": "Coltrane"}]},
+		 "feedback": {"thumbsDown": [{"feedbackId": "f1", "artistName": "Miles Davis", "songName": "So What", "isPositive": false}]}},
+		{"stationName": "Rock", "music": {"songs": [{"seedId": "b3", "artistName": "Band", "songName": "Hit"}]}},
+		{"stationName": "Only B", "music": {"artists": [{"seedId": "b4", "artistName": "Other"}]}}
+	]`), &b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d := DiffStations(a, b)
+	if len(d.OnlyA) != 1 || d.OnlyA[0].StationName != "Only A" {
+		t.Errorf("unexpected OnlyA %+v", d.OnlyA)
+	}
+	if len(d.OnlyB) != 1 || d.OnlyB[0].StationName != "Only B" {
+		t.Errorf("unexpected OnlyB %+v", d.OnlyB)
+	}
+	if len(d.Changed) != 2 {
+		t.Fatalf("expected 2 changed stations, got %d", len(d.Changed))
+	}
+
+	jazz := d.Changed[0]
+	if len(jazz.SeedsOnlyA) != 0 || len(jazz.SeedsOnlyB) != 1 || jazz.SeedsOnlyB[0].SeedID != "b2" {
+		t.Errorf("unexpected seed diff %+v %+v", jazz.SeedsOnlyA, jazz.SeedsOnlyB)
+	}
+	if len(jazz.Conflicts) != 1 || jazz.Conflicts[0][1].FeedbackID != "f1" {
+		t.Errorf("unexpected conflicts %+v", jazz.Conflicts)
+	}
+
+	// Matched by seeds, only the name differs.
+	rock := d.Changed[1]
+	if rock.A.StationName != "My Rock" || rock.B.StationName != "Rock" || len(rock.SeedsOnlyA)+len(rock.SeedsOnlyB) != 0 {
+		t.Errorf("unexpected change %+v", rock)
+	}
+
+	out := d.String()
+	for _, line := range []string{`+ "Only A" only in A`, `- seed Coltrane`, `! Miles Davis - So What: thumbs up in A, thumbs down in B`, `~ "My Rock" ("Rock" in B)`} {
+		if !strings.Contains(out, line) {
+			t.Errorf("diff is missing %q:\n%s", line, out)
+		}
+	}
+
+	if !DiffStations(a, a).Empty() {
+		t.Error("expected no difference between identical accounts")
+	}
+}
+
+func TestSyncStationsConflict(t *testing.T) {
+	account := func(name string, f response.FeedbackResponse) *Client {
+		c, _ := NewClient(AndroidClient)
+		c.Interceptors = []Interceptor{func(req request.Type, data interface{}, next Invoker) error {
+			switch req.(type) {
+			case request.GetStationList:
+				data.(*response.UserGetStationList).Stations = response.StationList{{StationToken: "st", StationName: "Jazz"}}
+			case request.GetStation:
+				data.(*response.StationGetStation).Result = response.Station{
+					StationToken: "st",
+					StationName:  "Jazz",
+					Music:        response.StationMusic{Artists: []response.ArtistSeed{{ArtistName: "Miles Davis"}}},
+					Feedback:     response.StationFeedback{ThumbsUp: []response.FeedbackResponse{f}},
+				}
+			case request.AddFeedback, request.DeleteFeedback:
+				t.Errorf("%s: unexpected %s", name, req.Method())
+			}
+			return nil
+		}}
+		return c
+	}
+
+	a := account("a", response.FeedbackResponse{FeedbackID: "fa", ArtistName: "Miles Davis", SongName: "So What", IsPositive: true})
+	b := account("b", response.FeedbackResponse{FeedbackID: "fb", ArtistName: "Miles Davis", SongName: "So What", IsPositive: false})
+
+	_, problems, err := SyncStations(a, b, SyncOptions{Direction: SyncBidirectional})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(problems) != 0 {
+		t.Errorf("expected conflicts to be skipped, got %v", problems)
+	}
+
+	_, problems, err = SyncStations(a, b, SyncOptions{Direction: SyncBidirectional, Conflicts: ConflictReport})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(problems) != 1 || problems[0].Err != ErrRatingConflict || problems[0].Music.SongName != "So What" {
+		t.Errorf("expected the conflict to be reported, got %v", problems)
+	}
+}
@@ -0,0 +1,82 @@
+package gopiano
+
+import (
+	"fmt"
+	"time"
+)
+
+// parseSyncTime extracts the Unix time from a decrypted sync time, which is
+// 4 garbage bytes followed by 10 digits and possibly padding.
+func parseSyncTime(plain []byte) (int64, error) {
+	if len(plain) < 14 {
+		return 0, fmt.Errorf("sync time too short: %q", plain)
+	}
+
+	var sec int64
+	for _, b := range plain[4:14] {
+		if b < '0' || b > '9' {
+			return 0, fmt.Errorf("invalid sync time: %q", plain)
+		}
+		sec = sec*10 + int64(b-'0')
+	}
+	if len(plain) > 14 && '0' <= plain[14] && plain[14] <= '9' {
+		return 0, fmt.Errorf("sync time too long: %q", plain)
+	}
+
+	return sec, nil
+}
+
+func (c *Client) now() time.Time {
+	if c.Clock != nil {
+		return c.Clock()
+	}
+	return time.Now()
+}
+
+// TimeOffset returns how far Pandora's clock is ahead of the local one, as
+// measured by the last AuthPartnerLogin or Resync.
+func (c *Client) TimeOffset() time.Duration {
+	c.sessionMu.Lock()
+	defer c.sessionMu.Unlock()
+	return c.timeOffset
+}
+
+// partnerToken returns the token of the last AuthPartnerLogin.
+func (c *Client) partnerToken() string {
+	c.sessionMu.Lock()
+	defer c.sessionMu.Unlock()
+	return c.partnerAuthToken
+}
+
+// Resync measures the offset to Pandora's clock again by repeating the
+// partner login. The user's auth token is kept, so later calls go on with
+// the same user session.
+func (c *Client) Resync() error {
+	_, err := c.AuthPartnerLogin()
+	return err
+}
+
+// maybeResync resyncs if ResyncInterval has passed since the last sync.
+// Errors are ignored; the old offset stays and the next call tries again.
+// Only one call resyncs at a time.
+func (c *Client) maybeResync() {
+	if c.ResyncInterval <= 0 {
+		return
+	}
+	now := c.now()
+
+	c.sessionMu.Lock()
+	due := !c.lastSync.IsZero() && !c.resyncing && now.Sub(c.lastSync) >= c.ResyncInterval
+	if due {
+		c.resyncing = true
+	}
+	c.sessionMu.Unlock()
+	if !due {
+		return
+	}
+
+	c.Resync()
+	c.sessionMu.Lock()
+	c.resyncing = false
+	c.sessionMu.Unlock()
+}
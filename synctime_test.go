@@ -0,0 +1,160 @@
+package gopiano
+
+import (
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/blowfish"
+
+	"denniskupec.com/gopiano/request"
+	"denniskupec.com/gopiano/response"
+)
+
+func TestParseSyncTime(t *testing.T) {
+	data := []struct {
+		Plain string
+		Sec   int64
+		Err   bool
+	}{
+		{"abcd1600000000", 1600000000, false},
+		{"\xff\x00\x01\x021600000000\x02\x02", 1600000000, false},
+		{"abcd160000000", 0, true},
+		{"abcd16000000x0", 0, true},
+		{"abcd16000000001", 0, true},
+		{"", 0, true},
+	}
+
+	for _, d := range data {
+		sec, err := parseSyncTime([]byte(d.Plain))
+		if (err != nil) != d.Err {
+			t.Errorf("%q: unexpected error %v", d.Plain, err)
+		} else if sec != d.Sec {
+			t.Errorf("%q: expected %d, got %d", d.Plain, d.Sec, sec)
+		}
+	}
+}
+
+// fakeSession answers partner logins with the sync time pandoraTime and
+// counts them. Other calls succeed without a result.
+func fakeSession(pandoraTime *int64, logins *int) Interceptor {
+	cipher, _ := blowfish.NewCipher([]byte(AndroidClient.DecryptKey))
+	return func(req request.Type, data interface{}, next Invoker) error {
+		if _, ok := req.(request.PartnerLogin); !ok {
+			return nil
+		}
+		*logins++
+
+		plain := []byte("abcd" + strconv.FormatInt(*pandoraTime, 10) + "\x00\x00")
+		for i := 0; i < len(plain); i += 8 {
+			cipher.Encrypt(plain[i:i+8], plain[i:i+8])
+		}
+		resp := data.(*response.AuthPartnerLogin)
+		resp.SyncTime = hex.EncodeToString(plain)
+		resp.PartnerID = "42"
+		resp.PartnerAuthToken = "partner-" + strconv.Itoa(*logins)
+		return nil
+	}
+}
+
+func TestResync(t *testing.T) {
+	now := time.Unix(1500000000, 0)
+	pandoraTime := int64(1500000100)
+	logins := 0
+
+	c, _ := NewClient(AndroidClient)
+	c.Clock = func() time.Time { return now }
+	c.ResyncInterval = time.Hour
+	c.Interceptors = []Interceptor{fakeSession(&pandoraTime, &logins)}
+
+	if _, err := c.AuthPartnerLogin(); err != nil {
+		t.Fatal(err)
+	}
+	if d := c.TimeOffset(); d != 100*time.Second {
+		t.Errorf("expected offset 100s, got %v", d)
+	}
+	if st := c.GetSyncTime(); st != 1500000100 {
+		t.Errorf("expected sync time 1500000100, got %d", st)
+	}
+
+	// Within the interval nothing happens.
+	now = now.Add(30 * time.Minute)
+	c.ExplainTrack("token")
+	if logins != 1 {
+		t.Errorf("expected 1 login, got %d", logins)
+	}
+
+	// Pandora's clock drifted; the next call after the interval notices.
+	now = now.Add(time.Hour)
+	pandoraTime = now.Unix() + 40
+	c.ExplainTrack("token")
+	if logins != 2 {
+		t.Errorf("expected 2 logins, got %d", logins)
+	}
+	if d := c.TimeOffset(); d != 40*time.Second {
+		t.Errorf("expected offset 40s, got %v", d)
+	}
+	if st := c.GetSyncTime(); st != int(now.Unix()+40) {
+		t.Errorf("expected sync time %d, got %d", now.Unix()+40, st)
+	}
+}
+
+func TestResyncKeepsUser(t *testing.T) {
+	pandoraTime := time.Now().Unix()
+	logins := 0
+
+	c, _ := NewClient(AndroidClient)
+	c.Interceptors = []Interceptor{fakeSession(&pandoraTime, &logins)}
+	if _, err := c.AuthPartnerLogin(); err != nil {
+		t.Fatal(err)
+	}
+	c.userAuthToken, c.userID = "user-token", "7"
+
+	if err := c.Resync(); err != nil {
+		t.Fatal(err)
+	}
+	if c.partnerAuthToken != "partner-2" {
+		t.Errorf("expected the new partner token, got %q", c.partnerAuthToken)
+	}
+	if tok := c.Token(); tok.UserAuthToken != "user-token" {
+		t.Errorf("expected the user token to be kept, got %q", tok.UserAuthToken)
+	}
+	u := c.formatURL(request.ExplainTrack{})
+	if !strings.Contains(u, "auth_token=user-token") || !strings.Contains(u, "user_id=7") {
+		t.Errorf("calls after a resync leave the user session: %s", u)
+	}
+}
+
+// TestResyncConcurrent is meant to be run with -race: calls resync while
+// other goroutines use the session.
+func TestResyncConcurrent(t *testing.T) {
+	pandoraTime := time.Now().Unix()
+	logins := 0
+
+	c, _ := NewClient(AndroidClient)
+	c.ResyncInterval = time.Nanosecond
+	c.Interceptors = []Interceptor{fakeSession(&pandoraTime, &logins)}
+	if _, err := c.AuthPartnerLogin(); err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				c.ExplainTrack("token")
+				c.TimeOffset()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if logins < 2 {
+		t.Errorf("expected resyncs, got %d logins", logins)
+	}
+}
@@ -0,0 +1,111 @@
+package tag
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"io/ioutil"
+)
+
+// WriteMP3 copies an MP3 (or ADTS AAC) stream from r to w, preceded by an
+// ID3v2.4 tag holding m. An ID3v2 tag already at the start of r is replaced.
+func WriteMP3(w io.Writer, r io.Reader, m Metadata) (int64, error) {
+	br := bufio.NewReader(r)
+	if err := skipID3(br); err != nil {
+		return 0, err
+	}
+
+	n, err := w.Write(ID3(m))
+	if err != nil {
+		return int64(n), err
+	}
+
+	c, err := io.Copy(w, br)
+	return int64(n) + c, err
+}
+
+// skipID3 discards an ID3v2 tag at the start of r.
+func skipID3(r *bufio.Reader) error {
+	head, err := r.Peek(10)
+	if err != nil || !bytes.HasPrefix(head, []byte("ID3")) {
+		return nil
+	}
+
+	size := int64(synchsafeInt(head[6:10])) + 10
+	if head[5]&0x10 != 0 { // footer present
+		size += 10
+	}
+
+	if n, err := io.CopyN(ioutil.Discard, r, size); n != size {
+		if err == nil || err == io.EOF {
+			err = errShortTag
+		}
+		return err
+	}
+	return nil
+}
+
+// ID3 returns an ID3v2.4 tag holding m.
+func ID3(m Metadata) []byte {
+	var frames bytes.Buffer
+
+	text := func(id, value string) {
+		if value != "" {
+			id3Frame(&frames, id, append([]byte{id3UTF8}, value...))
+		}
+	}
+	txxx := func(desc, value string) {
+		if value != "" {
+			data := append([]byte{id3UTF8}, desc...)
+			data = append(data, 0)
+			id3Frame(&frames, "TXXX", append(data, value...))
+		}
+	}
+
+	text("TIT2", m.Title)
+	text("TPE1", m.Artist)
+	text("TALB", m.Album)
+	if m.Gain != nil {
+		txxx("REPLAYGAIN_TRACK_GAIN", m.Gain.String())
+	}
+	txxx("PANDORA_TRACK_TOKEN", m.TrackToken)
+	txxx("PANDORA_STATION_ID", m.StationID)
+
+	if m.Art != nil {
+		data := append([]byte{id3UTF8}, m.Art.MIMEType...)
+		data = append(data, 0, id3FrontCover, 0) // empty description
+		id3Frame(&frames, "APIC", append(data, m.Art.Data...))
+	}
+
+	tag := make([]byte, 10, 10+frames.Len())
+	copy(tag, "ID3\x04\x00\x00")
+	putSynchsafe(tag[6:], frames.Len())
+
+	return append(tag, frames.Bytes()...)
+}
+
+const (
+	id3UTF8       = 0x03
+	id3FrontCover = 0x03
+)
+
+func id3Frame(buf *bytes.Buffer, id string, data []byte) {
+	var head [10]byte
+	copy(head[:], id)
+	putSynchsafe(head[4:], len(data))
+
+	buf.Write(head[:])
+	buf.Write(data)
+}
+
+// ID3v2.4 sizes use 7 bits per byte.
+func putSynchsafe(b []byte, n int) {
+	b[0] = byte(n>>21) & 0x7f
+	b[1] = byte(n>>14) & 0x7f
+	b[2] = byte(n>>7) & 0x7f
+	b[3] = byte(n) & 0x7f
+}
+
+func synchsafeInt(b []byte) int {
+	return int(b[0]&0x7f)<<21 | int(b[1]&0x7f)<<14 | int(b[2]&0x7f)<<7 | int(b[3]&0x7f)
+}
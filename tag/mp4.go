@@ -0,0 +1,303 @@
+package tag
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"io/ioutil"
+)
+
+var errNoMoov = errors.New("tag: no moov atom in MP4 file")
+
+// WriteM4A copies an MP4/M4A file from r to w with m stored as iTunes
+// metadata atoms in moov/udta/meta/ilst, replacing existing metadata.
+// The whole file is read into memory since the chunk offsets of the audio
+// data move if the metadata sits in front of it.
+func WriteM4A(w io.Writer, r io.Reader, m Metadata) (int64, error) {
+	file, err := ioutil.ReadAll(r)
+	if err != nil {
+		return 0, err
+	}
+
+	boxes, err := parseBoxes(file)
+	if err != nil {
+		return 0, err
+	}
+
+	at := -1
+	var moovStart int
+	for i, pos := 0, 0; i < len(boxes); i++ {
+		if boxes[i].typ == "moov" {
+			at, moovStart = i, pos
+			break
+		}
+		pos += boxes[i].size()
+	}
+	if at < 0 {
+		return 0, errNoMoov
+	}
+
+	moov, err := tagMoov(boxes[at], m)
+	if err != nil {
+		return 0, err
+	}
+
+	// Audio data after moov moves by the change in size.
+	if delta := int64(moov.size() - boxes[at].size()); delta != 0 {
+		if err := shiftChunkOffsets(moov, int64(moovStart), delta); err != nil {
+			return 0, err
+		}
+	}
+	boxes[at] = moov
+
+	var n int64
+	for _, b := range boxes {
+		c, err := b.writeTo(w)
+		n += c
+		if err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+// box is an MP4 atom. Container boxes that were parsed have children
+// instead of data.
+type box struct {
+	typ      string
+	data     []byte
+	children []*box
+	prefix   []byte // version and flags of full boxes like meta
+}
+
+func (b *box) size() int {
+	n := 8 + len(b.prefix) + len(b.data)
+	for _, c := range b.children {
+		n += c.size()
+	}
+	if n > 0xffffffff {
+		n += 8
+	}
+	return n
+}
+
+func (b *box) writeTo(w io.Writer) (int64, error) {
+	var buf bytes.Buffer
+	b.encode(&buf)
+	return buf.WriteTo(w)
+}
+
+func (b *box) encode(buf *bytes.Buffer) {
+	var head [16]byte
+	if size := b.size(); size > 0xffffffff {
+		binary.BigEndian.PutUint32(head[:], 1)
+		copy(head[4:], b.typ)
+		binary.BigEndian.PutUint64(head[8:], uint64(size))
+		buf.Write(head[:16])
+	} else {
+		binary.BigEndian.PutUint32(head[:], uint32(size))
+		copy(head[4:], b.typ)
+		buf.Write(head[:8])
+	}
+
+	buf.Write(b.prefix)
+	buf.Write(b.data)
+	for _, c := range b.children {
+		c.encode(buf)
+	}
+}
+
+func (b *box) child(typ string) *box {
+	for _, c := range b.children {
+		if c.typ == typ {
+			return c
+		}
+	}
+	return nil
+}
+
+var errBadBox = errors.New("tag: malformed MP4 atom")
+
+func parseBoxes(data []byte) ([]*box, error) {
+	var boxes []*box
+	for len(data) > 0 {
+		if len(data) < 8 {
+			return nil, errBadBox
+		}
+
+		size := int64(binary.BigEndian.Uint32(data))
+		typ := string(data[4:8])
+		head := int64(8)
+		switch size {
+		case 0: // extends to the end of the file
+			size = int64(len(data))
+		case 1:
+			if len(data) < 16 {
+				return nil, errBadBox
+			}
+			size = int64(binary.BigEndian.Uint64(data[8:]))
+			head = 16
+		}
+		if size < head || size > int64(len(data)) {
+			return nil, errBadBox
+		}
+
+		boxes = append(boxes, &box{typ: typ, data: data[head:size]})
+		data = data[size:]
+	}
+	return boxes, nil
+}
+
+// expand parses the data of a container box into children.
+func (b *box) expand() error {
+	if b.children != nil || b.data == nil {
+		return nil
+	}
+
+	children, err := parseBoxes(b.data)
+	if err != nil {
+		return err
+	}
+	b.children, b.data = children, nil
+	return nil
+}
+
+// Containers on the way from moov to the chunk offset tables.
+var containers = map[string]bool{
+	"moov": true,
+	"trak": true,
+	"mdia": true,
+	"minf": true,
+	"stbl": true,
+}
+
+func tagMoov(orig *box, m Metadata) (*box, error) {
+	moov := &box{typ: "moov", data: orig.data}
+	if err := moov.expand(); err != nil {
+		return nil, err
+	}
+
+	udta := moov.child("udta")
+	if udta == nil {
+		udta = &box{typ: "udta"}
+		moov.children = append(moov.children, udta)
+	} else if err := udta.expand(); err != nil {
+		return nil, err
+	}
+
+	var keep []*box
+	for _, c := range udta.children {
+		if c.typ != "meta" {
+			keep = append(keep, c)
+		}
+	}
+	udta.children = append(keep, metaBox(m))
+
+	return moov, nil
+}
+
+// shiftChunkOffsets adds delta to every stco/co64 entry pointing past start.
+func shiftChunkOffsets(b *box, start, delta int64) error {
+	for _, c := range b.children {
+		switch {
+		case containers[c.typ]:
+			if err := c.expand(); err != nil {
+				return err
+			}
+			if err := shiftChunkOffsets(c, start, delta); err != nil {
+				return err
+			}
+		case c.typ == "stco" || c.typ == "co64":
+			if err := shiftTable(c, start, delta); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func shiftTable(b *box, start, delta int64) error {
+	if len(b.data) < 8 {
+		return errBadBox
+	}
+
+	width := 4
+	if b.typ == "co64" {
+		width = 8
+	}
+	count := int(binary.BigEndian.Uint32(b.data[4:]))
+	if len(b.data) < 8+count*width {
+		return errBadBox
+	}
+
+	// Copy so the input file is left untouched.
+	table := append([]byte(nil), b.data...)
+	for i := 0; i < count; i++ {
+		p := table[8+i*width:]
+		if width == 4 {
+			if off := int64(binary.BigEndian.Uint32(p)); off > start {
+				binary.BigEndian.PutUint32(p, uint32(off+delta))
+			}
+		} else {
+			if off := int64(binary.BigEndian.Uint64(p)); off > start {
+				binary.BigEndian.PutUint64(p, uint64(off+delta))
+			}
+		}
+	}
+	b.data = table
+	return nil
+}
+
+// Type indicators of ilst data atoms.
+const (
+	itunesUTF8 = 1
+	itunesJPEG = 13
+	itunesPNG  = 14
+)
+
+func metaBox(m Metadata) *box {
+	ilst := &box{typ: "ilst", children: []*box{}}
+
+	text := func(typ, value string) {
+		if value != "" {
+			ilst.children = append(ilst.children, &box{typ: typ, children: []*box{dataBox(itunesUTF8, []byte(value))}})
+		}
+	}
+	freeform := func(name, value string) {
+		if value != "" {
+			ilst.children = append(ilst.children, &box{typ: "----", children: []*box{
+				{typ: "mean", prefix: make([]byte, 4), data: []byte("com.apple.iTunes")},
+				{typ: "name", prefix: make([]byte, 4), data: []byte(name)},
+				dataBox(itunesUTF8, []byte(value)),
+			}})
+		}
+	}
+
+	text("\xa9nam", m.Title)
+	text("\xa9ART", m.Artist)
+	text("\xa9alb", m.Album)
+	if m.Gain != nil {
+		freeform("REPLAYGAIN_TRACK_GAIN", m.Gain.String())
+	}
+	freeform("PANDORA_TRACK_TOKEN", m.TrackToken)
+	freeform("PANDORA_STATION_ID", m.StationID)
+
+	if m.Art != nil {
+		typ := uint32(itunesJPEG)
+		if m.Art.MIMEType == "image/png" {
+			typ = itunesPNG
+		}
+		ilst.children = append(ilst.children, &box{typ: "covr", children: []*box{dataBox(typ, m.Art.Data)}})
+	}
+
+	hdlr := &box{typ: "hdlr", prefix: make([]byte, 4), data: []byte("\x00\x00\x00\x00mdirappl\x00\x00\x00\x00\x00\x00\x00\x00\x00")}
+
+	return &box{typ: "meta", prefix: make([]byte, 4), children: []*box{hdlr, ilst}}
+}
+
+func dataBox(typ uint32, value []byte) *box {
+	prefix := make([]byte, 8) // type indicator and locale
+	binary.BigEndian.PutUint32(prefix, typ)
+	return &box{typ: "data", prefix: prefix, data: value}
+}
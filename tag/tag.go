@@ -0,0 +1,139 @@
+/*
+Package tag embeds track metadata into saved Pandora audio streams: ID3v2.4
+tags for MP3 (and ADTS AAC) and iTunes style atoms for MP4/M4A files.
+*/
+package tag
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+
+	"denniskupec.com/gopiano/response"
+)
+
+// Largest album art that will be embedded.
+const maxArtSize = 10 << 20
+
+// Metadata to embed into an audio file.
+type Metadata struct {
+	Title  string
+	Artist string
+	Album  string
+
+	// ArtURL is fetched by FetchArt to fill in Art.
+	ArtURL string
+	Art    *Picture
+
+	// Pandora identifiers of the track.
+	TrackToken string
+	StationID  string
+
+	// Gain is stored as REPLAYGAIN_TRACK_GAIN. Nil if unknown, in which
+	// case no ReplayGain tag is written.
+	Gain *response.Gain
+}
+
+// Picture is embedded album art.
+type Picture struct {
+	MIMEType string
+	Data     []byte
+}
+
+// FromPlaylist returns the metadata of every song in a playlist, leaving out ads.
+func FromPlaylist(p *response.StationGetPlaylist) []Metadata {
+	var md []Metadata
+	for i := range p.Items {
+		if p.Items[i].IsAd() || p.Items[i].SongName == "" {
+			continue
+		}
+		md = append(md, FromItem(&p.Items[i]))
+	}
+	return md
+}
+
+// FromItem returns the metadata of a playlist item.
+func FromItem(item *response.PlaylistItem) Metadata {
+	m := Metadata{
+		Title:      item.SongName,
+		Artist:     item.ArtistName,
+		Album:      item.AlbumName,
+		ArtURL:     item.AlbumArtURL,
+		TrackToken: item.TrackToken,
+		StationID:  item.StationID,
+	}
+	if item.TrackGain.Valid {
+		gain := item.TrackGain.Gain
+		m.Gain = &gain
+	}
+	return m
+}
+
+// FetchArt downloads ArtURL into Art unless there already is art or no URL.
+// A nil client uses http.DefaultClient.
+func (m *Metadata) FetchArt(client *http.Client) error {
+	if m.Art != nil || m.ArtURL == "" {
+		return nil
+	}
+
+	pic, err := FetchArt(client, m.ArtURL)
+	if err != nil {
+		return err
+	}
+	m.Art = pic
+	return nil
+}
+
+// FetchArt downloads an image to embed. A nil client uses http.DefaultClient.
+func FetchArt(client *http.Client, url string) (*Picture, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching art %s: %s", url, resp.Status)
+	}
+
+	data, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxArtSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxArtSize {
+		return nil, fmt.Errorf("fetching art %s: image too large", url)
+	}
+
+	typ, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if err != nil || typ != "image/jpeg" && typ != "image/png" {
+		typ = http.DetectContentType(data)
+	}
+
+	return &Picture{MIMEType: typ, Data: data}, nil
+}
+
+// Write copies the audio stream from r to w with metadata m, picking the
+// tag format from the stream: iTunes atoms for MP4 files and ID3v2 otherwise.
+func Write(w io.Writer, r io.Reader, m Metadata) (int64, error) {
+	br := bufio.NewReader(r)
+	head, err := br.Peek(8)
+	if err != nil && err != io.EOF {
+		return 0, err
+	}
+
+	if len(head) == 8 && bytes.Equal(head[4:8], []byte("ftyp")) {
+		return WriteM4A(w, br, m)
+	}
+	return WriteMP3(w, br, m)
+}
+
+var errShortTag = errors.New("tag: truncated ID3v2 tag")
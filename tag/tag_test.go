@@ -0,0 +1,147 @@
+package tag
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"denniskupec.com/gopiano/response"
+)
+
+var testGain = response.Gain(-3.31)
+
+var md = Metadata{
+	Title:      "Title",
+	Artist:     "Artist",
+	Album:      "Album",
+	TrackToken: "token",
+	Gain:       &testGain,
+	Art:        &Picture{MIMEType: "image/png", Data: []byte("\x89PNG")},
+}
+
+func TestWriteMP3(t *testing.T) {
+	audio := []byte("\xff\xfbaudio")
+
+	var first bytes.Buffer
+	if _, err := Write(&first, bytes.NewReader(audio), md); err != nil {
+		t.Fatal(err)
+	}
+	out := first.Bytes()
+	if !bytes.HasPrefix(out, []byte("ID3\x04")) || !bytes.HasSuffix(out, audio) {
+		t.Fatalf("unexpected output %q", out)
+	}
+	if size := synchsafeInt(out[6:10]); size != len(out)-10-len(audio) {
+		t.Errorf("tag size %d does not match %d", size, len(out)-10-len(audio))
+	}
+	for _, s := range []string{"TIT2", "TPE1", "TALB", "APIC", "REPLAYGAIN_TRACK_GAIN\x00-3.31 dB", "PANDORA_TRACK_TOKEN\x00token"} {
+		if !bytes.Contains(out, []byte(s)) {
+			t.Errorf("missing %q", s)
+		}
+	}
+
+	// Tagging again replaces the tag.
+	var second bytes.Buffer
+	if _, err := WriteMP3(&second, bytes.NewReader(out), md); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(second.Bytes(), out) {
+		t.Error("retagging did not replace the existing tag")
+	}
+}
+
+func atom(typ string, payload ...[]byte) []byte {
+	data := bytes.Join(payload, nil)
+	b := make([]byte, 8, 8+len(data))
+	binary.BigEndian.PutUint32(b, uint32(8+len(data)))
+	copy(b[4:], typ)
+	return append(b, data...)
+}
+
+func TestUnknownGain(t *testing.T) {
+	m := FromItem(&response.PlaylistItem{SongName: "Title", TrackGain: response.NullGain{}})
+	if m.Gain != nil {
+		t.Fatalf("expected no gain, got %v", *m.Gain)
+	}
+	if bytes.Contains(ID3(m), []byte("REPLAYGAIN")) {
+		t.Error("ID3 tag has a ReplayGain frame without a gain")
+	}
+
+	m = FromItem(&response.PlaylistItem{SongName: "Title", TrackGain: response.NullGain{Gain: -1.5, Valid: true}})
+	if m.Gain == nil || *m.Gain != -1.5 {
+		t.Errorf("expected gain -1.5, got %v", m.Gain)
+	}
+
+	// 0 dB is a gain like any other.
+	m = FromItem(&response.PlaylistItem{SongName: "Title", TrackGain: response.NullGain{Valid: true}})
+	if m.Gain == nil || *m.Gain != 0 {
+		t.Errorf("expected gain 0, got %v", m.Gain)
+	}
+	if !bytes.Contains(ID3(m), []byte("REPLAYGAIN_TRACK_GAIN")) {
+		t.Error("ID3 tag has no ReplayGain frame for a gain of 0 dB")
+	}
+}
+
+func TestWriteM4A(t *testing.T) {
+	ftyp := atom("ftyp", []byte("M4A \x00\x00\x00\x00"))
+	stco := func(offset uint32) []byte {
+		table := make([]byte, 12)
+		binary.BigEndian.PutUint32(table[4:], 1)
+		binary.BigEndian.PutUint32(table[8:], offset)
+		return atom("stco", table)
+	}
+	moovLen := len(atom("moov", atom("trak", atom("mdia", atom("minf", atom("stbl", stco(0)))))))
+	offset := uint32(len(ftyp) + moovLen + 8)
+	moov := atom("moov", atom("trak", atom("mdia", atom("minf", atom("stbl", stco(offset))))))
+	mdat := atom("mdat", []byte("chunk"))
+
+	var out bytes.Buffer
+	if _, err := Write(&out, bytes.NewReader(bytes.Join([][]byte{ftyp, moov, mdat}, nil)), md); err != nil {
+		t.Fatal(err)
+	}
+
+	file := out.Bytes()
+	boxes, err := parseBoxes(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(boxes) != 3 || boxes[1].typ != "moov" || boxes[2].typ != "mdat" {
+		t.Fatalf("unexpected layout %v", boxes)
+	}
+
+	stbl := boxes[1]
+	for _, typ := range []string{"trak", "mdia", "minf", "stbl", "stco"} {
+		if err := stbl.expand(); err != nil {
+			t.Fatal(err)
+		}
+		if stbl = stbl.child(typ); stbl == nil {
+			t.Fatalf("missing %s", typ)
+		}
+	}
+	off := binary.BigEndian.Uint32(stbl.data[8:])
+	if got := string(file[off : off+5]); got != "chunk" {
+		t.Errorf("chunk offset %d points at %q", off, got)
+	}
+
+	for _, s := range []string{"udta", "meta", "ilst", "\xa9nam", "covr", "REPLAYGAIN_TRACK_GAIN"} {
+		if !bytes.Contains(file, []byte(s)) {
+			t.Errorf("missing %q", s)
+		}
+	}
+}
+
+func TestFetchArt(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("\xff\xd8\xff\xe0\x00\x10JFIF"))
+	}))
+	defer srv.Close()
+
+	m := Metadata{ArtURL: srv.URL}
+	if err := m.FetchArt(nil); err != nil {
+		t.Fatal(err)
+	}
+	if m.Art == nil || m.Art.MIMEType != "image/jpeg" {
+		t.Errorf("unexpected art %+v", m.Art)
+	}
+}
@@ -21,7 +21,7 @@ func (c *Client) UserCanSubscribe() (*response.UserCanSubscribe, error) {
 // countryCode must be "US".
 func (c *Client) UserCreateUser(username, password, gender, countryCode string, zipCode, birthYear int, emailOptin bool) (*response.UserCreateUser, error) {
 	requestData := request.CreateUser{
-		PartnerAuthToken: c.partnerAuthToken,
+		PartnerAuthToken: c.partnerToken(),
 		AccountType:      "registered",
 		RegisteredType:   "user",
 		Username:         username,
@@ -40,6 +40,8 @@ func (c *Client) UserCreateUser(username, password, gender, countryCode string,
 	}
 
 	// Set user data onto client for later use.
+	c.sessionMu.Lock()
+	defer c.sessionMu.Unlock()
 	c.userAuthToken = resp.UserAuthToken
 	c.userID = resp.UserID
 
@@ -50,7 +52,7 @@ func (c *Client) UserCreateUser(username, password, gender, countryCode string,
 func (c *Client) UserEmailPassword(username string) error {
 	requestData := request.EmailPassword{
 		Username:         username,
-		PartnerAuthToken: c.partnerAuthToken,
+		PartnerAuthToken: c.partnerToken(),
 		SyncTime:         c.GetSyncTime(),
 	}
 
@@ -78,6 +80,19 @@ func (c *Client) UserGetStationList(includeStationArtURL bool) (*response.UserGe
 	return &resp, c.Call(requestData, &resp)
 }
 
+// UserGetStationListArt gets the list of a users stations including their art
+// in the given size, e.g. "W130H130".
+func (c *Client) UserGetStationListArt(artSize string) (*response.UserGetStationList, error) {
+	requestData := request.GetStationList{
+		IncludeStationArtURL: true,
+		StationArtSize:       artSize,
+		UserToken:            c.Token(),
+	}
+
+	var resp response.UserGetStationList
+	return &resp, c.Call(requestData, &resp)
+}
+
 // UserGetStationListChecksum returns the checksum of the user's station list.
 func (c *Client) UserGetStationListChecksum() (*response.UserGetStationListChecksum, error) {
 	requestData := request.GetStationListChecksum(c.Token())